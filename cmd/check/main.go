@@ -18,7 +18,13 @@ func main() {
 		log.Fatalf("failed to unmarshal request: %s", err)
 	}
 
-	response, err := resource.Check(request, resource.NewArtifactHubClient())
+	options, err := request.Source.ClientOptions()
+
+	if err != nil {
+		log.Fatalf("invalid source: %s", err)
+	}
+
+	response, err := resource.Check(request, resource.NewArtifactHubClient(options...))
 
 	if err != nil {
 		log.Fatalf("resource check failed with: %s", err)
@@ -24,7 +24,13 @@ func main() {
 
 	outputDir := os.Args[1]
 
-	response, err := resource.Get(request, outputDir, resource.NewArtifactHubClient())
+	options, err := request.Source.ClientOptions()
+
+	if err != nil {
+		log.Fatalf("invalid source: %s", err)
+	}
+
+	response, err := resource.Get(request, outputDir, resource.NewArtifactHubClient(options...))
 
 	if err != nil {
 		log.Fatalf("get failed: %s", err)
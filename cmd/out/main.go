@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	"log"
+	"os"
+)
+
+func main() {
+
+	var request resource.PutRequest
+
+	decoder := json.NewDecoder(os.Stdin)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&request); err != nil {
+		log.Fatalf("failed to unmarshal request: %s", err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatalf("missing arguments")
+	}
+
+	sourceDir := os.Args[1]
+
+	options, err := request.Source.ClientOptions()
+
+	if err != nil {
+		log.Fatalf("invalid source: %s", err)
+	}
+
+	response, err := resource.Put(request, sourceDir, resource.NewArtifactHubClient(options...))
+
+	if err != nil {
+		log.Fatalf("put failed: %s", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		log.Fatalf("failed to marshal response: %s", err)
+	}
+}
@@ -5,14 +5,25 @@ package e2e_test
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
+	"io/ioutil"
+	"math/big"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -126,10 +137,6 @@ var _ = Describe("E2E Check Resource", func() {
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(result).To(Equal([]resource.Version{
-				{
-					CreatedAt: time.Date(2020, 11, 19, 17, 22, 8, 0, time.UTC),
-					Version:   "9.2.0",
-				},
 				{
 					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
 					Version:   "9.2.4",
@@ -138,6 +145,131 @@ var _ = Describe("E2E Check Resource", func() {
 
 		})
 
+		It("it should send the api key id and secret", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.VerifyHeader(http.Header{
+					"X-Api-Key-Id":     []string{"MY_KEY_ID"},
+					"X-Api-Key-Secret": []string{"MY_KEY_SECRET"},
+				}),
+				func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("Authorization")).To(BeEmpty())
+				},
+				ghttp.RespondWith(http.StatusOK, jsonResponse),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\", \"api_key_id\": \"MY_KEY_ID\", \"api_key_secret\": \"MY_KEY_SECRET\"} }", token),
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+		})
+
+		It("it should request the package of the given kind", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/kyverno/acme-policies/some-package"),
+				ghttp.RespondWith(http.StatusOK, jsonResponse),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"kind\": \"kyverno\", \"repository_name\": \"acme-policies\", \"package_name\": \"some-package\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+		})
+
+		It("it should retry rate limited requests after the requested time", func() {
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+					ghttp.RespondWith(http.StatusTooManyRequests, nil, http.Header{"Retry-After": []string{"1"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+					ghttp.RespondWith(http.StatusOK, jsonResponse),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session, 5*time.Second).Should(Exit(0))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+		})
+
+		It("it should fail when the maximum retry wait is exceeded", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, nil, http.Header{"Retry-After": []string{"5"}}),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"max_retry_wait\": \"2s\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(1))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+
+		})
+
+		It("it should report the error returned by artifacthub", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.RespondWith(http.StatusNotFound, `{"message": "package not found"}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(gbytes.Say("returned status code: 404 \\(request id: some-request-id\\) with message: package not found"))
+
+		})
+
+		It("it should advise to check the api key which was sent", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.RespondWith(http.StatusUnauthorized, nil),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\"} }", token),
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(gbytes.Say("check that api_key is valid"))
+
+		})
+
 		It("it should order by version", func() {
 
 			jsonResponse = unorderedVersionResponse()
@@ -151,7 +283,7 @@ var _ = Describe("E2E Check Resource", func() {
 
 			session = executeCheckCommand(
 				execPath,
-				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\"} }", token),
+				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\"}, \"version\": {\"version\": \"9.1.5\"} }", token),
 				[]string{"/opt/resource/check"},
 				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
 			)
@@ -179,6 +311,237 @@ var _ = Describe("E2E Check Resource", func() {
 
 		})
 
+		It("it should skip versions which are not valid semver and report them on stderr", func() {
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.RespondWith(http.StatusOK, `{
+  "name": "some-package",
+  "available_versions": [
+    {"version": "9.2.4", "ts": 1606316622},
+    {"version": "not-a-version", "ts": 1606405343},
+    {"version": "9.2.0", "ts": 1605806528}
+  ]
+}`),
+			))
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"}, \"version\": {\"version\": \"9.2.0\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Err).To(gbytes.Say("skipping version not-a-version"))
+
+			var result = []resource.Version{}
+			err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&result)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]resource.Version{
+				{
+					CreatedAt: time.Date(2020, 11, 19, 17, 22, 8, 0, time.UTC),
+					Version:   "9.2.0",
+				},
+				{
+					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
+					Version:   "9.2.4",
+				},
+			}))
+
+		})
+
+	})
+
+	When("check is executed with a cache directory", func() {
+		It("it should answer unchanged packages from the cache", func() {
+
+			cacheDir, err := ioutil.TempDir("", "resource-cache-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(cacheDir)
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("If-None-Match")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusOK, jsonResponse, http.Header{"ETag": []string{`"some-etag"`}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+					ghttp.VerifyHeader(http.Header{"If-None-Match": []string{`"some-etag"`}}),
+					ghttp.RespondWith(http.StatusNotModified, nil),
+				),
+			)
+
+			var results [][]resource.Version
+
+			for i := 0; i < 2; i++ {
+				session = executeCheckCommand(
+					execPath,
+					fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"cache_dir\": \"%s\"} }", cacheDir),
+					[]string{"/opt/resource/check"},
+					"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+				)
+
+				Eventually(session).Should(Exit(0))
+
+				var result []resource.Version
+				err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&result)
+				Expect(err).ToNot(HaveOccurred())
+				results = append(results, result)
+			}
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+			Expect(results[1]).To(Equal(results[0]))
+			Expect(results[1]).To(Equal([]resource.Version{
+				{
+					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
+					Version:   "9.2.4",
+				},
+			}))
+		})
+	})
+
+	When("check is executed against a server with mutual TLS", func() {
+
+		var tlsServer *ghttp.Server
+
+		BeforeEach(func() {
+			clientCert, clientKey := selfSignedClientCertificate()
+			pool := x509.NewCertPool()
+			Expect(pool.AppendCertsFromPEM(clientCert)).To(BeTrue())
+
+			tlsServer = ghttp.NewUnstartedServer()
+			tlsServer.HTTPTestServer.TLS = &tls.Config{
+				ClientAuth: tls.RequireAndVerifyClientCert,
+				ClientCAs:  pool,
+			}
+			tlsServer.HTTPTestServer.StartTLS()
+
+			tlsServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+				ghttp.RespondWith(http.StatusOK, jsonResponse),
+			))
+
+			source := map[string]interface{}{
+				"repository_name": "acme-charts",
+				"package_name":    "some-package",
+				"base_url":        tlsServer.URL(),
+				"ca_cert": string(pem.EncodeToMemory(&pem.Block{
+					Type:  "CERTIFICATE",
+					Bytes: tlsServer.HTTPTestServer.Certificate().Raw,
+				})),
+				"client_cert": string(clientCert),
+				"client_key":  string(clientKey),
+				"timeout":     "5s",
+			}
+			request, err := json.Marshal(map[string]interface{}{"source": source})
+			Expect(err).ToNot(HaveOccurred())
+
+			session = executeCheckCommand(execPath, string(request), []string{"/opt/resource/check"})
+		})
+
+		AfterEach(func() {
+			tlsServer.Close()
+		})
+
+		It("it should trust the ca cert and authenticate with the client cert", func() {
+			Eventually(session).Should(Exit(0))
+
+			var result []resource.Version
+			err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&result)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]resource.Version{
+				{
+					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
+					Version:   "9.2.4",
+				},
+			}))
+		})
+	})
+
+	When("check is executed with an organization", func() {
+		It("it should search all pages and return a composite version", func() {
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/search", "facets=false&limit=60&offset=0&org=acme"),
+					ghttp.RespondWith(http.StatusOK,
+						`{"packages": [{"name": "some-package", "version": "9.2.4", "ts": 1606316622, "repository": {"name": "acme-charts"}}]}`,
+						http.Header{"Pagination-Total-Count": []string{"2"}},
+					),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/search", "facets=false&limit=60&offset=60&org=acme"),
+					ghttp.RespondWith(http.StatusOK,
+						`{"packages": [{"name": "other-package", "version": "1.0.0", "ts": 1605806528, "repository": {"name": "acme-plugins"}}]}`,
+						http.Header{"Pagination-Total-Count": []string{"2"}},
+					),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"organization\": \"acme\"} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+			var result []resource.Version
+			err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&result)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(1))
+			Expect(result[0].CreatedAt).To(Equal(time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)))
+			Expect(result[0].Version).To(HaveLen(16))
+			Expect(result[0].Manifest).To(BeEmpty())
+		})
+	})
+
+	When("check is executed with a search", func() {
+		It("it should resolve the package and return its latest version", func() {
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/search", "facets=false&kind=0&limit=11&offset=0&official=true&ts_query_web=some-package&verified_publisher=true"),
+					ghttp.RespondWith(http.StatusOK,
+						`{"packages": [{"name": "some-package", "version": "9.2.4", "repository": {"name": "acme-charts", "kind": 0}}]}`,
+						http.Header{"Pagination-Total-Count": []string{"1"}},
+					),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package"),
+					ghttp.RespondWith(http.StatusOK, jsonResponse),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"search\": \"some-package\", \"kind\": \"helm\", \"verified_publisher\": true, \"official\": true} }",
+				[]string{"/opt/resource/check"},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+			var result []resource.Version
+			err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&result)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]resource.Version{
+				{
+					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
+					Version:   "9.2.4",
+					Package:   "helm/acme-charts/some-package",
+				},
+			}))
+		})
 	})
 })
 
@@ -252,3 +615,27 @@ func unorderedVersionResponse() string {
   }
 }`
 }
+
+func selfSignedClientCertificate() ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "artifacthub-resource"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	privateKey, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKey})
+}
@@ -4,16 +4,19 @@ package e2e_test
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -153,6 +156,13 @@ var _ = Describe("E2E In Resource", func() {
 				{Name: "repository_name", Value: "acme-charts"},
 				{Name: "repository_display_name", Value: "Acme Charts"},
 				{Name: "version", Value: "9.2.4"},
+				{Name: "description", Value: "SomePackage is an open sourced code quality scanning tool"},
+				{Name: "home_url", Value: "https://www.example.local/"},
+				{Name: "maintainers", Value: "acme <acme@gmail.com>"},
+				{Name: "links", Value: "source: https://git.local/SomePackage/docker-some-package"},
+				{Name: "deprecated", Value: "false"},
+				{Name: "signed", Value: "false"},
+				{Name: "digest", Value: "d0a4a8230cd5e23beff38131e3aad706bcab97a79c6bf26a94318957271a8c6e"},
 			}))
 		})
 
@@ -162,6 +172,177 @@ var _ = Describe("E2E In Resource", func() {
 			testFileContainsExpectedText(tmpDir, "name", "some-package")
 			testFileContainsExpectedText(tmpDir, "chart_download_url",
 				"https://git.local/acme/charts/releases/download/some-package-9.2.4/some-package-9.2.4.tgz")
+			testFileContainsExpectedText(tmpDir, "digest",
+				"d0a4a8230cd5e23beff38131e3aad706bcab97a79c6bf26a94318957271a8c6e")
+			testFileContainsExpectedText(tmpDir, "deprecated", "false")
+			testFileContainsExpectedText(tmpDir, "dependencies.json", "[]")
+			testFileContainsExpectedText(tmpDir, "README.md", "# README")
+		})
+
+	})
+
+	When("in is executed with values", func() {
+
+		It("should write the values of the chart", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.RespondWith(http.StatusOK, fakeArtifactHubJsonResponse),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/be378d3f-d6c5-47ac-a2ae-0cb5d9f6d8f5/9.2.4/values"),
+					ghttp.RespondWith(http.StatusOK, "replicaCount: 1\n"),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"}, \"version\": {\"version\":\"9.2.4\"}, \"params\": {\"values\": true} }",
+				[]string{"/opt/resource/in", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+			testFileContainsExpectedText(tmpDir, "values.yaml", "replicaCount: 1\n")
+		})
+
+	})
+
+	When("in is executed for a version with a security report", func() {
+
+		BeforeEach(func() {
+			response := strings.Replace(
+				fakeArtifactHubJsonResponse,
+				`"security_report_created_at": 1608740109,`,
+				`"security_report_created_at": 1608740109,
+  "security_report_summary": {"high": 3, "medium": 5},`,
+				1,
+			)
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/be378d3f-d6c5-47ac-a2ae-0cb5d9f6d8f5/9.2.4/security-report"),
+					ghttp.RespondWith(http.StatusOK, `{"some-image": {"Results": []}}`),
+				),
+			)
+		})
+
+		It("should write the security summary and report", func() {
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"}, \"version\": {\"version\":\"9.2.4\"}, \"params\": {\"security_report\": true} }",
+				[]string{"/opt/resource/in", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+			summary, err := ioutil.ReadFile(tmpDir + "/security_summary.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(summary).To(MatchJSON(`{"critical": 0, "high": 3, "medium": 5, "low": 0, "unknown": 0}`))
+			testFileContainsExpectedText(tmpDir, "security_report.json", `{"some-image": {"Results": []}}`)
+		})
+
+		It("should fail when the max severity is exceeded", func() {
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"}, \"version\": {\"version\":\"9.2.4\"}, \"params\": {\"max_severity\": \"medium\"} }",
+				[]string{"/opt/resource/in", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(1))
+		})
+
+	})
+
+	When("in is executed with download_chart", func() {
+
+		BeforeEach(func() {
+			response := strings.Replace(
+				fakeArtifactHubJsonResponse,
+				"https://git.local/acme/charts/releases/download/some-package-9.2.4",
+				server.URL()+"/charts",
+				1,
+			)
+			response = strings.Replace(
+				response,
+				"d0a4a8230cd5e23beff38131e3aad706bcab97a79c6bf26a94318957271a8c6e",
+				fmt.Sprintf("%x", sha256.Sum256([]byte("chart-content"))),
+				1,
+			)
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/charts/some-package-9.2.4.tgz"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("Authorization")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusOK, "chart-content"),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\"}, \"version\": {\"created_at\":\"2020-11-25T16:03:42+01:00\",\"version\":\"9.2.4\"}, \"params\": {\"download_chart\": true} }", apiToken),
+				[]string{"/opt/resource/in", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("should download the chart without sending the api key", func() {
+			testFileContainsExpectedText(tmpDir, "some-package-9.2.4.tgz", "chart-content")
+		})
+
+	})
+
+	When("in is executed with download_chart for a chart without digest", func() {
+
+		It("should download the chart and warn that it could not be verified", func() {
+			response := strings.Replace(
+				fakeArtifactHubJsonResponse,
+				"https://git.local/acme/charts/releases/download/some-package-9.2.4",
+				server.URL()+"/charts",
+				1,
+			)
+			response = strings.Replace(
+				response,
+				`"digest": "d0a4a8230cd5e23beff38131e3aad706bcab97a79c6bf26a94318957271a8c6e",`,
+				"",
+				1,
+			)
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/charts/some-package-9.2.4.tgz"),
+					ghttp.RespondWith(http.StatusOK, "chart-content"),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				"{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\"}, \"version\": {\"version\":\"9.2.4\"}, \"params\": {\"download_chart\": true} }",
+				[]string{"/opt/resource/in", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Err).To(gbytes.Say("warning: some-package-9.2.4.tgz could not be verified"))
+			testFileContainsExpectedText(tmpDir, "some-package-9.2.4.tgz", "chart-content")
 		})
 
 	})
@@ -0,0 +1,99 @@
+// +build e2e
+
+package e2e_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+)
+
+var _ = Describe("E2E Out Resource", func() {
+
+	var (
+		apiToken                    string
+		server                      *ghttp.Server
+		execPath                    string
+		session                     *Session
+		tmpDir                      string
+		fakeArtifactHubJsonResponse = `
+{
+  "name": "some-package",
+  "version": "9.2.4",
+  "app_version": "8.5.1-community",
+  "content_url": "https://git.local/acme/charts/releases/download/some-package-9.2.4/some-package-9.2.4.tgz",
+  "ts": 1606316622,
+  "repository": {
+    "name": "acme-charts",
+    "display_name": "Acme Charts",
+    "url": "https://acme.github.io/charts",
+    "organization_display_name": "Acme"
+  }
+}`
+	)
+
+	BeforeEach(func() {
+		execPath = buildExec("github.com/hdisysteme/artifacthub-resource/cmd/out")
+		server = ghttp.NewServer()
+		apiToken = "MY_SECRET_TOKEN"
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "resource-test-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		CleanupBuildArtifacts()
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	When("out is executed with a version", func() {
+
+		It("it should wait for the version to be available", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.VerifyHeader(http.Header{
+						"Authorization": []string{"Bearer " + apiToken},
+					}),
+					ghttp.RespondWith(http.StatusNotFound, nil),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/packages/helm/acme-charts/some-package/9.2.4"),
+					ghttp.RespondWith(http.StatusOK, fakeArtifactHubJsonResponse),
+				),
+			)
+
+			session = executeCheckCommand(
+				execPath,
+				fmt.Sprintf("{ \"source\": {\"repository_name\": \"acme-charts\", \"package_name\": \"some-package\", \"api_key\": \"%s\"}, \"params\": {\"version\": \"9.2.4\", \"interval\": \"10ms\"} }", apiToken),
+				[]string{"/opt/resource/out", tmpDir},
+				"ARTIFACTHUB_BASE_URL=http://"+server.Addr(),
+			)
+
+			Eventually(session).Should(Exit(0))
+
+			var response = resource.PutResponse{}
+			err := json.NewDecoder(bytes.NewBuffer(session.Out.Contents())).Decode(&response)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Version).To(Equal(
+				resource.Version{
+					CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC),
+					Version:   "9.2.4",
+				}))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+	})
+})
@@ -1,37 +1,135 @@
-// Package resource provides functions for obtaining artifacthub.io Helm Chart versions
+// Package resource provides functions for obtaining artifacthub.io package versions
 package resource
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"github.com/Masterminds/semver/v3"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
-	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
+const userAgent = "artifacthub-resource/0.1"
+
+// searchLimit is the maximum page size of the artifacthub search api
+const searchLimit = 60
+
 // NewArtifactHubClient returns an ArtifactHubClient that contains a HTTP client that is already preconfigured.
 //
 // The contained http.Client is configured as follows.
-// http.Timeout = 10sec
-// http.Transport = http.ProxyFromEnvironment
+// http.Transport = http.ProxyFromEnvironment, see WithProxyURL
+// The default TLS configuration is used, see WithTLSConfig
+// Client certificates of the TLS configuration are only sent to the host of the base url
+// Each request attempt times out after 10sec, see WithTimeout
+// Requests failing with HTTP 429 or 5xx are retried for at most 1min, see WithMaxRetryWait
+// Responses are not cached, see WithCacheDir
 //
 // The Base URL is https://artifacthub.io and can be overwritten by the Environment Variable ARTIFACTHUB_BASE_URL
-func NewArtifactHubClient() ArtifactHubClient {
-	return ArtifactHubClient{
-		client: &http.Client{
-			Timeout:   10 * time.Second,
-			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+// or WithBaseURL
+func NewArtifactHubClient(options ...ClientOption) ArtifactHubClient {
+	config := clientConfig{
+		baseUrl:      baseUrl(),
+		proxy:        http.ProxyFromEnvironment,
+		timeout:      10 * time.Second,
+		maxRetryWait: time.Minute,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	var host string
+
+	if u, err := url.Parse(config.baseUrl); err == nil {
+		host = u.Host
+	}
+
+	var transport http.RoundTripper = &retryTransport{
+		transport: &scopedTransport{
+			host: host,
+			transport: &http.Transport{
+				Proxy:           config.proxy,
+				TLSClientConfig: config.tlsConfig,
+			},
+			fallback: &http.Transport{
+				Proxy:           config.proxy,
+				TLSClientConfig: withoutCertificates(config.tlsConfig),
+			},
 		},
-		baseUrl: baseUrl(),
+		timeout:   config.timeout,
+		maxWait:   config.maxRetryWait,
+		baseDelay: time.Second,
+		maxDelay:  30 * time.Second,
+	}
+
+	if len(config.cacheDir) > 0 {
+		transport = &cacheTransport{
+			transport:  transport,
+			dir:        config.cacheDir,
+			maxEntries: maxCacheEntries,
+			maxSize:    maxCacheSize,
+		}
+	}
+
+	return ArtifactHubClient{
+		client:  &http.Client{Transport: transport},
+		baseUrl: config.baseUrl,
+	}
+}
+
+// WithBaseURL overwrites the base url of artifacthub, e.g. for a self-hosted instance
+func WithBaseURL(baseUrl string) ClientOption {
+	return func(c *clientConfig) {
+		c.baseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
+// WithProxyURL sends all requests through the given proxy instead of the proxy of the environment
+func WithProxyURL(proxyUrl *url.URL) ClientOption {
+	return func(c *clientConfig) {
+		c.proxy = http.ProxyURL(proxyUrl)
 	}
 }
 
-// ListHelmVersion returns a specific HelmVersion of the given Package
-func (a ArtifactHubClient) ListHelmVersion(p Package, version string) (*HelmVersion, error) {
-	url := fmt.Sprintf("%s/api/v1/packages/helm/%s/%s/%s", a.baseUrl, p.RepositoryName, p.PackageName, version)
+// WithTLSConfig overwrites the TLS configuration, e.g. for a custom CA or client certificates
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithTimeout overwrites the timeout of each request attempt
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithCacheDir caches the responses of artifacthub in the given directory.
+// Cached responses are revalidated with If-None-Match and If-Modified-Since requests.
+// The least recently used responses are removed if the cache exceeds 1000 responses or 64MiB.
+func WithCacheDir(dir string) ClientOption {
+	return func(c *clientConfig) {
+		c.cacheDir = dir
+	}
+}
+
+// WithMaxRetryWait limits the total time spent waiting between retries of a request
+func WithMaxRetryWait(maxRetryWait time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.maxRetryWait = maxRetryWait
+	}
+}
+
+// ListVersion returns a specific PackageVersion of the given Package
+func (a ArtifactHubClient) ListVersion(p Package, version string) (*PackageVersion, error) {
+	url := fmt.Sprintf("%s/%s", a.packageUrl(p), version)
 	request, err := http.NewRequest("GET", url, nil)
 
 	if err != nil {
@@ -48,14 +146,10 @@ func (a ArtifactHubClient) ListHelmVersion(p Package, version string) (*HelmVers
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"artifacthub http request returned status code: %d with message: %w",
-			response.StatusCode,
-			err,
-		)
+		return nil, newAPIError(response)
 	}
 
-	var target HelmVersion
+	var target PackageVersion
 	err = json.NewDecoder(response.Body).Decode(&target)
 
 	if err != nil {
@@ -65,11 +159,11 @@ func (a ArtifactHubClient) ListHelmVersion(p Package, version string) (*HelmVers
 	return &target, nil
 }
 
-// ListHelmVersions lists all available versions for the given Package
-// The []Version is returned in descending order of the Version
-func (a ArtifactHubClient) ListHelmVersions(p Package) ([]Version, error) {
+// ListVersions lists all available versions for the given Package
+// The []Version is returned in the order provided by artifacthub
+func (a ArtifactHubClient) ListVersions(p Package) ([]Version, error) {
 
-	url := fmt.Sprintf("%s/api/v1/packages/helm/%s/%s", a.baseUrl, p.RepositoryName, p.PackageName)
+	url := a.packageUrl(p)
 	request, err := http.NewRequest("GET", url, nil)
 
 	if err != nil {
@@ -87,47 +181,198 @@ func (a ArtifactHubClient) ListHelmVersions(p Package) ([]Version, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"artifacthub http request returned status code: %d with message: %w",
-			response.StatusCode,
-			err,
-		)
+		return nil, newAPIError(response)
 	}
 
-	var target HelmVersion
+	var target PackageVersion
 	err = json.NewDecoder(response.Body).Decode(&target)
 
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal JSON: %s", err)
 	}
 
-	sort.Slice(target.AvailableVersions, func(i, j int) bool {
-		version, err := semver.NewVersion(target.AvailableVersions[i].Version)
+	var versions []Version
+
+	// artifacthub only tells whether the latest version is deprecated, available versions have no such flag
+	for _, version := range target.AvailableVersions {
+		versions = append(versions, Version{
+			CreatedAt:  time.Time(version.TS).UTC(),
+			Version:    version.Version,
+			Deprecated: target.Deprecated && version.Version == target.Version,
+		})
+	}
+
+	return versions, nil
+
+}
+
+// SearchPackages returns all packages matching the SearchQuery.
+// The results of all pages are returned unless the query has a limit,
+// each result contains the latest version of the package.
+func (a ArtifactHubClient) SearchPackages(p Package, query SearchQuery) ([]SearchResult, error) {
+	if query.Limit > 0 {
+		page, _, err := a.searchPage(p, query, 0)
+		return page, err
+	}
+
+	var results []SearchResult
+
+	for offset := 0; ; offset += searchLimit {
+		page, total, err := a.searchPage(p, query, offset)
 
 		if err != nil {
-			printError(target.Name, target.AvailableVersions[i], nil)
+			return nil, err
 		}
 
-		otherVersion, err := semver.NewVersion(target.AvailableVersions[j].Version)
+		results = append(results, page...)
 
-		if err != nil {
-			printError(target.Name, target.AvailableVersions[j], err)
+		if len(page) == 0 || len(results) >= total {
+			return results, nil
 		}
+	}
+}
 
-		return version.LessThan(otherVersion)
-	})
+// searchPage returns a single page of search results starting at offset and the total count of results
+func (a ArtifactHubClient) searchPage(p Package, query SearchQuery, offset int) ([]SearchResult, int, error) {
+	params := query.values()
+	params.Set("limit", strconv.Itoa(searchLimit))
 
-	var versions []Version
+	if query.Limit > 0 {
+		params.Set("limit", strconv.Itoa(query.Limit))
+	}
 
-	for _, version := range target.AvailableVersions {
-		versions = append(versions, Version{
-			CreatedAt: time.Time(version.TS).UTC(),
-			Version:   version.Version,
-		})
+	params.Set("offset", strconv.Itoa(offset))
+
+	url := fmt.Sprintf("%s/api/v1/packages/search?%s", a.baseUrl, params.Encode())
+	request, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("build new artifacthub http request failed: %s", err)
 	}
 
-	return versions, nil
+	prepareHttpHeader(p, request)
+
+	response, err := a.client.Do(request)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while requesting artifacthub: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, newAPIError(response)
+	}
+
+	var target struct {
+		Packages []SearchResult `json:"packages"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&target)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not marshal JSON: %s", err)
+	}
+
+	total, err := strconv.Atoi(response.Header.Get("Pagination-Total-Count"))
+
+	if err != nil {
+		total = offset + len(target.Packages)
+	}
+
+	return target.Packages, total, nil
+}
+
+// SecurityReport returns the full security report of the given package version as JSON
+func (a ArtifactHubClient) SecurityReport(p Package, packageID string, version string) ([]byte, error) {
+	return a.get(p, fmt.Sprintf("%s/api/v1/packages/%s/%s/security-report", a.baseUrl, packageID, version))
+}
 
+// Values returns the default values of the given helm chart version as YAML
+func (a ArtifactHubClient) Values(p Package, packageID string, version string) ([]byte, error) {
+	return a.get(p, fmt.Sprintf("%s/api/v1/packages/%s/%s/values", a.baseUrl, packageID, version))
+}
+
+// ValuesSchema returns the values schema of the given helm chart version as JSON
+func (a ArtifactHubClient) ValuesSchema(p Package, packageID string, version string) ([]byte, error) {
+	return a.get(p, fmt.Sprintf("%s/api/v1/packages/%s/%s/values-schema", a.baseUrl, packageID, version))
+}
+
+// Changelog returns the changelog of the given package
+func (a ArtifactHubClient) Changelog(p Package, packageID string) ([]ChangelogEntry, error) {
+	body, err := a.get(p, fmt.Sprintf("%s/api/v1/packages/%s/changelog", a.baseUrl, packageID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ChangelogEntry
+
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %s", err)
+	}
+
+	return entries, nil
+}
+
+// get returns the body of a successful artifacthub GET request
+func (a ArtifactHubClient) get(p Package, url string) ([]byte, error) {
+	request, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("build new artifacthub http request failed: %s", err)
+	}
+
+	prepareHttpHeader(p, request)
+
+	response, err := a.client.Do(request)
+
+	if err != nil {
+		return nil, fmt.Errorf("error while requesting artifacthub: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, newAPIError(response)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("error while reading artifacthub response: %w", err)
+	}
+
+	return body, nil
+}
+
+// Download writes the content of the given url to w.
+// No artifacthub credentials are sent, as the url usually points to a different host.
+func (a ArtifactHubClient) Download(url string, w io.Writer) error {
+	request, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return fmt.Errorf("build new download http request failed: %s", err)
+	}
+
+	request.Header.Add("User-Agent", userAgent)
+
+	response, err := a.client.Do(request)
+
+	if err != nil {
+		return fmt.Errorf("error while downloading %s: %w", url, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return newAPIError(response)
+	}
+
+	if _, err := io.Copy(w, response.Body); err != nil {
+		return fmt.Errorf("error while downloading %s: %w", url, err)
+	}
+
+	return nil
 }
 
 // MarshalJSON marshals an Epoch into a formatted time.RFC3339 representation
@@ -152,8 +397,26 @@ func (t Epoch) String() string { return time.Time(t).String() }
 // ArtifactHub is the interface implemented by
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o ./fakes/fake_artifacthub.go . ArtifactHub
 type ArtifactHub interface {
-	ListHelmVersions(p Package) ([]Version, error)
-	ListHelmVersion(p Package, version string) (*HelmVersion, error)
+	ListVersions(p Package) ([]Version, error)
+	ListVersion(p Package, version string) (*PackageVersion, error)
+	Download(url string, w io.Writer) error
+	SecurityReport(p Package, packageID string, version string) ([]byte, error)
+	Values(p Package, packageID string, version string) ([]byte, error)
+	ValuesSchema(p Package, packageID string, version string) ([]byte, error)
+	Changelog(p Package, packageID string) ([]ChangelogEntry, error)
+	SearchPackages(p Package, query SearchQuery) ([]SearchResult, error)
+}
+
+// ClientOption configures the ArtifactHubClient returned by NewArtifactHubClient
+type ClientOption func(c *clientConfig)
+
+type clientConfig struct {
+	baseUrl      string
+	proxy        func(*http.Request) (*url.URL, error)
+	tlsConfig    *tls.Config
+	timeout      time.Duration
+	maxRetryWait time.Duration
+	cacheDir     string
 }
 
 // ArtifactHubClient is used to query the artifacthub.io endpoint.
@@ -162,13 +425,13 @@ type ArtifactHubClient struct {
 	baseUrl string
 }
 
-func printError(name string, target AvailableVersion, err error) {
-	fmt.Println(fmt.Sprintf(
-		"Error while getting semver version for package %s and version %s with error: %v",
-		name,
-		target.Version,
-		err,
-	))
+// packageUrl returns the url of the Package which depends on the kind of the Package
+func (a ArtifactHubClient) packageUrl(p Package) string {
+	kind := p.Kind
+	if len(kind) == 0 {
+		kind = KindHelm
+	}
+	return fmt.Sprintf("%s/api/v1/packages/%s/%s/%s", a.baseUrl, kind, p.RepositoryName, p.PackageName)
 }
 
 func baseUrl() string {
@@ -181,51 +444,153 @@ func baseUrl() string {
 }
 
 func prepareHttpHeader(p Package, request *http.Request) {
-	request.Header.Add("User-Agent", "artifacthub-resource/0.1")
+	request.Header.Add("User-Agent", userAgent)
 	request.Header.Add("Accept", "application/json")
 
+	if len(p.ApiKeyID) > 0 && len(p.ApiKeySecret) > 0 {
+		request.Header.Add("X-API-KEY-ID", p.ApiKeyID)
+		request.Header.Add("X-API-KEY-SECRET", p.ApiKeySecret)
+		return
+	}
+
 	if len(p.ApiKey) > 0 {
 		request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", p.ApiKey))
 	}
 }
 
-// Package represents an artifacthub Helm Package
+// Package represents an artifacthub Package of a specific kind
 type Package struct {
+	Kind           string
 	RepositoryName string
 	PackageName    string
 	ApiKey         string
+	ApiKeyID       string
+	ApiKeySecret   string
 }
 
 // Epoch is an alias for time.Time
 type Epoch time.Time
 
-// AvailableVersion represents a version and version timestamp for a HelmVersion
+// AvailableVersion represents a version and version timestamp for a PackageVersion
 type AvailableVersion struct {
 	Version string `json:"version"`
 	TS      Epoch  `json:"ts"`
 }
 
-// Repository represents information about the repository of a HelmVersion
+// Repository represents information about the repository of a PackageVersion
 type Repository struct {
 	Url                     string `json:"url"`
 	DisplayName             string `json:"display_name"`
 	Name                    string `json:"name"`
+	OrganizationName        string `json:"organization_name"`
 	OrganizationDisplayName string `json:"organization_display_name"`
+	Kind                    int    `json:"kind"`
+	VerifiedPublisher       bool   `json:"verified_publisher"`
+	Official                bool   `json:"official"`
+}
+
+// SearchQuery contains the filters of a package search, empty filters are not applied
+type SearchQuery struct {
+	Text              string
+	Repository        string
+	Organization      string
+	Kind              string
+	VerifiedPublisher bool
+	Official          bool
+	// Limit requests only the first page with at most Limit results instead of all pages
+	Limit int
+}
+
+// values returns the query parameters of the SearchQuery
+func (q SearchQuery) values() url.Values {
+	params := url.Values{}
+	params.Set("facets", "false")
+
+	if len(q.Text) > 0 {
+		params.Set("ts_query_web", q.Text)
+	}
+
+	if len(q.Repository) > 0 {
+		params.Set("repo", q.Repository)
+	}
+
+	if len(q.Organization) > 0 {
+		params.Set("org", q.Organization)
+	}
+
+	if id, ok := kindID(q.Kind); ok {
+		params.Set("kind", strconv.Itoa(id))
+	}
+
+	if q.VerifiedPublisher {
+		params.Set("verified_publisher", "true")
+	}
+
+	if q.Official {
+		params.Set("official", "true")
+	}
+
+	return params
+}
+
+// SearchResult represents a package found by SearchPackages together with its latest version
+type SearchResult struct {
+	PackageID  string     `json:"package_id"`
+	Name       string     `json:"name"`
+	Version    string     `json:"version"`
+	TS         Epoch      `json:"ts"`
+	Deprecated bool       `json:"deprecated"`
+	Repository Repository `json:"repository"`
+}
+
+// Maintainer represents a maintainer of a PackageVersion
+type Maintainer struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Link represents a named link of a PackageVersion
+type Link struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
 }
 
-// HelmVersion represents a helm chart package version
-type HelmVersion struct {
-	AppVersion        string             `json:"app_version"`
-	ContentUrl        string             `json:"content_url"`
-	TS                Epoch              `json:"ts"`
-	Name              string             `json:"name"`
-	Version           string             `json:"version"`
-	AvailableVersions []AvailableVersion `json:"available_versions"`
-	Repository        Repository         `json:"repository"`
+// PackageVersion represents a package version of any kind
+type PackageVersion struct {
+	PackageID             string             `json:"package_id"`
+	SecurityReportSummary map[string]int     `json:"security_report_summary"`
+	AppVersion            string             `json:"app_version"`
+	ContentUrl            string             `json:"content_url"`
+	TS                    Epoch              `json:"ts"`
+	Name                  string             `json:"name"`
+	Version               string             `json:"version"`
+	Digest                string             `json:"digest"`
+	Deprecated            bool               `json:"deprecated"`
+	Readme                string             `json:"readme"`
+	HasValuesSchema       bool               `json:"has_values_schema"`
+	HasChangelog          bool               `json:"has_changelog"`
+	Data                  PackageData        `json:"data"`
+	Description           string             `json:"description"`
+	HomeUrl               string             `json:"home_url"`
+	License               string             `json:"license"`
+	Maintainers           []Maintainer       `json:"maintainers"`
+	Links                 []Link             `json:"links"`
+	Signed                bool               `json:"signed"`
+	Signatures            []string           `json:"signatures"`
+	AvailableVersions     []AvailableVersion `json:"available_versions"`
+	Repository            Repository         `json:"repository"`
 }
 
-// Version represents a specific version for a HelmVersion
+// Version represents a specific version for a PackageVersion
+// The Deprecated flag is only used to filter versions and is not part of the concourse version.
+// It is only known for the latest version of a package.
 type Version struct {
-	CreatedAt time.Time `json:"created_at"`
-	Version   string    `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	Version      string    `json:"version"`
+	Dependencies string    `json:"dependencies,omitempty"`
+	Manifest     string    `json:"manifest,omitempty"`
+	Package      string    `json:"package,omitempty"`
+	Previous     string    `json:"previous,omitempty"`
+	Changes      string    `json:"changes,omitempty"`
+	Deprecated   bool      `json:"-"`
 }
@@ -0,0 +1,194 @@
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxCacheEntries limits the number of responses in the cache directory
+	maxCacheEntries = 1000
+	// maxCacheSize limits the total size of the responses in the cache directory in bytes
+	maxCacheSize = 64 << 20
+)
+
+// cacheTransport sends conditional requests for artifacthub api requests and
+// answers them from the cache directory if artifacthub reports the response as not modified.
+//
+// Responses are cached by their url if they contain an ETag or Last-Modified header.
+// The credentials are not part of the key, as artifacthub still decides who gets a not modified response.
+// The least recently used responses are removed when the cache exceeds maxEntries or maxSize.
+// Failures to read or write the cache are ignored and the request is sent unconditionally.
+type cacheTransport struct {
+	transport  http.RoundTripper
+	dir        string
+	maxEntries int
+	maxSize    int64
+}
+
+// cacheEntry is a cached response persisted as JSON in the cache directory
+type cacheEntry struct {
+	ETag         string      `json:"etag"`
+	LastModified string      `json:"last_modified"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != http.MethodGet || request.Header.Get("Accept") != "application/json" {
+		return t.transport.RoundTrip(request)
+	}
+
+	filename := filepath.Join(t.dir, cacheKey(request)+".json")
+	entry, cached := readCacheEntry(filename)
+
+	if cached {
+		request = request.Clone(request.Context())
+
+		if len(entry.ETag) > 0 {
+			request.Header.Set("If-None-Match", entry.ETag)
+		}
+
+		if len(entry.LastModified) > 0 {
+			request.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	response, err := t.transport.RoundTrip(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && response.StatusCode == http.StatusNotModified {
+		_ = response.Body.Close()
+
+		now := time.Now()
+		_ = os.Chtimes(filename, now, now)
+
+		response.StatusCode = http.StatusOK
+		response.Status = "200 OK"
+		response.Header = entry.Header
+		response.ContentLength = int64(len(entry.Body))
+		response.Body = ioutil.NopCloser(bytes.NewReader(entry.Body))
+
+		return response, nil
+	}
+
+	etag := response.Header.Get("ETag")
+	lastModified := response.Header.Get("Last-Modified")
+
+	if response.StatusCode != http.StatusOK || (len(etag) == 0 && len(lastModified) == 0) {
+		return response, nil
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	_ = response.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	writeCacheEntry(t.dir, filename, cacheEntry{
+		ETag:         etag,
+		LastModified: lastModified,
+		Header:       response.Header,
+		Body:         body,
+	})
+	t.prune()
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return response, nil
+}
+
+// cacheKey returns a key for the url of the request
+func cacheKey(request *http.Request) string {
+	sum := sha256.Sum256([]byte(request.URL.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// prune removes the least recently used entries until the cache is within maxEntries and maxSize
+func (t *cacheTransport) prune() {
+	files, err := ioutil.ReadDir(t.dir)
+
+	if err != nil {
+		return
+	}
+
+	var entries []os.FileInfo
+	var size int64
+
+	for _, file := range files {
+		if file.Mode().IsRegular() && strings.HasSuffix(file.Name(), ".json") {
+			entries = append(entries, file)
+			size += file.Size()
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+
+	for len(entries) > 0 && (len(entries) > t.maxEntries || size > t.maxSize) {
+		_ = os.Remove(filepath.Join(t.dir, entries[0].Name()))
+		size -= entries[0].Size()
+		entries = entries[1:]
+	}
+}
+
+func readCacheEntry(filename string) (cacheEntry, bool) {
+	var entry cacheEntry
+
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
+
+	if err != nil {
+		return entry, false
+	}
+
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// writeCacheEntry writes the entry to a temporary file first, so concurrent requests never read a partial entry
+func writeCacheEntry(dir string, filename string, entry cacheEntry) {
+	content, err := json.Marshal(entry)
+
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+
+	file, err := ioutil.TempFile(dir, "entry-")
+
+	if err != nil {
+		return
+	}
+
+	_, err = file.Write(content)
+
+	if closeErr := file.Close(); err != nil || closeErr != nil {
+		_ = os.Remove(file.Name())
+		return
+	}
+
+	if err := os.Rename(file.Name(), filename); err != nil {
+		_ = os.Remove(file.Name())
+	}
+}
@@ -0,0 +1,186 @@
+package resource_test
+
+import (
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Cache Transport", func() {
+
+	var (
+		cacheDir  string
+		requests  []*http.Request
+		responses []*http.Response
+		transport http.RoundTripper
+	)
+
+	BeforeEach(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "resource-cache-")
+		Expect(err).ToNot(HaveOccurred())
+
+		requests = nil
+		responses = nil
+		transport = resource.NewCacheTransport(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request)
+			response := responses[0]
+			responses = responses[1:]
+			return response, nil
+		}), cacheDir, 10, 1<<20)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cacheDir)
+	})
+
+	get := func(url string, header http.Header) (int, string) {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.Header = header
+
+		response, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body.Close()).To(Succeed())
+
+		return response.StatusCode, string(body)
+	}
+
+	jsonHeader := func() http.Header {
+		return http.Header{"Accept": {"application/json"}}
+	}
+
+	respond := func(statusCode int, header http.Header, body string) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	cacheEntries := func() []string {
+		matches, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		Expect(err).ToNot(HaveOccurred())
+		return matches
+	}
+
+	It("should answer a not modified response from the cache", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{"Etag": {`"v1"`}, "Content-Type": {"application/json"}}, `{"name": "some-package"}`),
+			respond(http.StatusNotModified, http.Header{}, ""),
+		}
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+		statusCode, body := get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(body).To(Equal(`{"name": "some-package"}`))
+		Expect(requests[0].Header.Get("If-None-Match")).To(BeEmpty())
+		Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+	})
+
+	It("should revalidate with the last modified date", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{"Last-Modified": {"Wed, 25 Nov 2020 15:03:42 GMT"}}, `{}`),
+			respond(http.StatusNotModified, http.Header{}, ""),
+		}
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+
+		Expect(requests[1].Header.Get("If-Modified-Since")).To(Equal("Wed, 25 Nov 2020 15:03:42 GMT"))
+	})
+
+	It("should replace the cached response with a modified response", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, `{"version": "1"}`),
+			respond(http.StatusOK, http.Header{"Etag": {`"v2"`}}, `{"version": "2"}`),
+			respond(http.StatusNotModified, http.Header{}, ""),
+		}
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+		_, body := get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+
+		Expect(requests[2].Header.Get("If-None-Match")).To(Equal(`"v2"`))
+		Expect(body).To(Equal(`{"version": "2"}`))
+	})
+
+	It("should share cached responses between credentials", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, `{}`),
+			respond(http.StatusUnauthorized, http.Header{}, ""),
+		}
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", http.Header{"Accept": {"application/json"}, "Authorization": {"Bearer some-key"}})
+		statusCode, _ := get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", http.Header{"Accept": {"application/json"}, "Authorization": {"Bearer other-key"}})
+
+		Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		Expect(statusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should bypass the cache for requests which do not accept JSON", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, "chart-content"),
+			respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, "chart-content"),
+		}
+
+		get("https://git.local/charts/some-package-9.2.4.tgz", http.Header{})
+		_, body := get("https://git.local/charts/some-package-9.2.4.tgz", http.Header{})
+
+		Expect(body).To(Equal("chart-content"))
+		Expect(requests[1].Header.Get("If-None-Match")).To(BeEmpty())
+		Expect(cacheEntries()).To(BeEmpty())
+	})
+
+	It("should not cache responses without ETag or Last-Modified", func() {
+		responses = []*http.Response{
+			respond(http.StatusOK, http.Header{}, `{}`),
+		}
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/some-package", jsonHeader())
+
+		Expect(cacheEntries()).To(BeEmpty())
+	})
+
+	It("should remove the least recently used responses beyond the maximum number of entries", func() {
+		transport = resource.NewCacheTransport(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request)
+			return respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, `{}`), nil
+		}), cacheDir, 2, 1<<20)
+
+		for _, name := range []string{"first", "second", "third"} {
+			get("https://artifacthub.io/api/v1/packages/helm/acme-charts/"+name, jsonHeader())
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		Expect(cacheEntries()).To(HaveLen(2))
+
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/first", jsonHeader())
+		get("https://artifacthub.io/api/v1/packages/helm/acme-charts/third", jsonHeader())
+
+		Expect(requests[3].Header.Get("If-None-Match")).To(BeEmpty())
+		Expect(requests[4].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+	})
+
+	It("should remove responses beyond the maximum size", func() {
+		transport = resource.NewCacheTransport(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			return respond(http.StatusOK, http.Header{"Etag": {`"v1"`}}, strings.Repeat("x", 512)), nil
+		}), cacheDir, 10, 1024)
+
+		for _, name := range []string{"first", "second", "third"} {
+			get("https://artifacthub.io/api/v1/packages/helm/acme-charts/"+name, jsonHeader())
+		}
+
+		Expect(len(cacheEntries())).To(BeNumerically("<", 2))
+	})
+})
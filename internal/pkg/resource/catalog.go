@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// catalog returns true if the Source names only a repository or an organization
+// and all packages of it are tracked
+func (s Source) catalog() bool {
+	return len(s.Packages) == 0 && len(s.PackageName) == 0 && len(s.Search) == 0 &&
+		(len(s.RepositoryName) > 0 || len(s.Organization) > 0)
+}
+
+// validateCatalog checks that no options are given which require all versions of a package
+func (s Source) validateCatalog() error {
+	if len(s.VersionConstraint) > 0 || len(s.PreReleases) > 0 || len(s.MinAge) > 0 || s.TrackDependencies {
+		return fmt.Errorf("version_constraint, pre_releases, min_age and track_dependencies are not supported for all packages of a repository or organization")
+	}
+	return nil
+}
+
+// checkCatalog returns a single composite version of the latest versions of all packages
+// of the repository or organization. The manifest is not part of the version, as it can list
+// thousands of packages. Instead the version contains the digest of the previous version and
+// the packages published since the previous version, as the previous manifest is not known.
+func (c CheckRequest) checkCatalog(repository ArtifactHub) ([]Version, error) {
+	packages, versions, err := c.Source.catalogPackages(repository)
+
+	if err != nil {
+		return nil, err
+	}
+
+	version := compositeVersion(packages, versions)
+	version.Manifest = ""
+
+	if version.Version == c.Version.Version {
+		return []Version{c.Version}, nil
+	}
+
+	if len(c.Version.Version) > 0 {
+		version.Previous = c.Version.Version
+		version.Changes = publishedAfter(packages, versions, c.Version.CreatedAt)
+	}
+
+	return []Version{version}, nil
+}
+
+// catalogPackages returns the latest versions of all packages of the repository or organization
+// as found by the search api
+func (s Source) catalogPackages(repository ArtifactHub) ([]PackageSource, []Version, error) {
+	results, err := repository.SearchPackages(s.pkg(), SearchQuery{
+		Repository:   s.RepositoryName,
+		Organization: s.Organization,
+		Kind:         s.Kind,
+	})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search packages: %w", explain(err))
+	}
+
+	var packages []PackageSource
+	var versions []Version
+
+	for _, result := range results {
+		if result.Deprecated && !s.IncludeDeprecated {
+			continue
+		}
+
+		packages = append(packages, PackageSource{
+			RepositoryName: result.Repository.Name,
+			PackageName:    result.Name,
+		})
+		versions = append(versions, Version{
+			CreatedAt: time.Time(result.TS).UTC(),
+			Version:   result.Version,
+		})
+	}
+
+	if len(packages) == 0 {
+		return nil, nil, fmt.Errorf("no packages found for repository: %s organization: %s", s.RepositoryName, s.Organization)
+	}
+
+	return packages, versions, nil
+}
+
+// publishedAfter returns the manifest entries of the versions published after the given time
+func publishedAfter(packages []PackageSource, versions []Version, since time.Time) string {
+	var entries []string
+
+	for i, version := range versions {
+		if version.CreatedAt.After(since) {
+			entries = append(entries, fmt.Sprintf("%s@%s", packages[i].name(), version.Version))
+		}
+	}
+
+	sort.Strings(entries)
+
+	return strings.Join(entries, ",")
+}
+
+// getCatalog writes the packages changed by the version and the current versions of all packages,
+// the packages itself are not fetched. The current versions differ from the version if the packages
+// changed since the check, this is reported on stderr.
+func getCatalog(request GetRequest, path string, repository ArtifactHub) (*GetResponse, error) {
+	packages, versions, err := request.Source.catalogPackages(repository)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current := compositeVersion(packages, versions)
+
+	if current.Version != request.Version.Version {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: the packages changed since version %s was checked, packages.json lists the current versions\n", request.Version.Version)
+	}
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %s", err)
+	}
+
+	changes, err := writeManifest(path, current.Manifest, request.Version.Changes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata = &Metadata{}
+
+	for _, change := range changes {
+		metadata.append(change.name(), change.Version)
+	}
+
+	return &GetResponse{
+		Version:  request.Version,
+		Metadata: *metadata,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ChangelogEntry represents the changes of a package version
+type ChangelogEntry struct {
+	Version string            `json:"version"`
+	TS      Epoch             `json:"ts"`
+	Changes []ChangelogChange `json:"changes"`
+}
+
+// ChangelogChange represents a single change of a ChangelogEntry
+type ChangelogChange struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// UnmarshalJSON unmarshals a change that is either given as object or as plain description
+func (c *ChangelogChange) UnmarshalJSON(data []byte) error {
+	var description string
+
+	if err := json.Unmarshal(data, &description); err == nil {
+		c.Description = description
+		return nil
+	}
+
+	type change ChangelogChange
+	return json.Unmarshal(data, (*change)(c))
+}
+
+// changelog renders the changelog entries after since up to and including the version as markdown.
+// If since is empty, the previous version of the available versions is used.
+// If the version is not valid semver, only the entry of the version itself is rendered.
+func changelog(entries []ChangelogEntry, version *PackageVersion, since string) (string, error) {
+	current, err := semver.NewVersion(version.Version)
+
+	if err != nil {
+		for _, e := range entries {
+			if e.Version == version.Version {
+				return renderChangelog([]ChangelogEntry{e}), nil
+			}
+		}
+		return renderChangelog(nil), nil
+	}
+
+	if len(since) == 0 {
+		since = previousVersion(version.AvailableVersions, current)
+	}
+
+	var lower *semver.Version
+
+	if len(since) > 0 {
+		lower, err = semver.NewVersion(since)
+
+		if err != nil {
+			return "", fmt.Errorf("invalid since_version %s: %s", since, err)
+		}
+	}
+
+	type entry struct {
+		version *semver.Version
+		ChangelogEntry
+	}
+
+	var selected []entry
+
+	for _, e := range entries {
+		v, err := semver.NewVersion(e.Version)
+
+		if err != nil || v.GreaterThan(current) || (lower != nil && !v.GreaterThan(lower)) {
+			continue
+		}
+
+		selected = append(selected, entry{version: v, ChangelogEntry: e})
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].version.GreaterThan(selected[j].version)
+	})
+
+	var ordered []ChangelogEntry
+
+	for _, e := range selected {
+		ordered = append(ordered, e.ChangelogEntry)
+	}
+
+	return renderChangelog(ordered), nil
+}
+
+// renderChangelog renders the entries as markdown in the given order
+func renderChangelog(entries []ChangelogEntry) string {
+	var builder strings.Builder
+	builder.WriteString("# Changelog\n")
+
+	for _, e := range entries {
+		builder.WriteString(fmt.Sprintf("\n## %s\n\n", e.Version))
+
+		for _, change := range e.Changes {
+			if len(change.Kind) > 0 {
+				builder.WriteString(fmt.Sprintf("- %s%s: %s\n", strings.ToUpper(change.Kind[:1]), change.Kind[1:], change.Description))
+			} else {
+				builder.WriteString(fmt.Sprintf("- %s\n", change.Description))
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// previousVersion returns the highest available semver version lower than current
+func previousVersion(versions []AvailableVersion, current *semver.Version) string {
+	var previous *semver.Version
+	var name string
+
+	for _, version := range versions {
+		v, err := semver.NewVersion(version.Version)
+
+		if err != nil || !v.LessThan(current) {
+			continue
+		}
+
+		if previous == nil || v.GreaterThan(previous) {
+			previous = v
+			name = version.Version
+		}
+	}
+
+	return name
+}
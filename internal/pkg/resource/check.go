@@ -1,8 +1,19 @@
 package resource
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
 
-// Check for CheckRequest will fetch all versions of a given helm chart
+// Check for CheckRequest will fetch all versions of a given package
+// that are equal to or newer than the requested version.
+//
+// If no version is requested only the latest version is returned.
+// If the requested version does not exist anymore, the latest version is returned.
+// For multiple packages a single composite version of the latest versions of all packages is returned.
+// The same applies to all packages of a repository or organization.
+// A searched package is pinned in the versions, so get does not need to search it again.
 func Check(request CheckRequest, repository ArtifactHub) (*[]Version, error) {
 
 	err := request.validate()
@@ -11,12 +22,37 @@ func Check(request CheckRequest, repository ArtifactHub) (*[]Version, error) {
 		return nil, err
 	}
 
-	var versions []Version
-	versions, err = repository.ListHelmVersions(Package{
-		RepositoryName: request.Source.RepositoryName,
-		PackageName:    request.Source.PackageName,
-		ApiKey:         request.Source.ApiKey,
-	})
+	if len(request.Source.Search) > 0 {
+		versions, err := request.checkSearch(repository)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &versions, nil
+	}
+
+	if len(request.Source.Packages) > 0 {
+		versions, err := request.checkPackages(repository)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &versions, nil
+	}
+
+	if request.Source.catalog() {
+		versions, err := request.checkCatalog(repository)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &versions, nil
+	}
+
+	versions, err := request.versions(repository)
 
 	if err != nil {
 		return nil, err
@@ -25,26 +61,257 @@ func Check(request CheckRequest, repository ArtifactHub) (*[]Version, error) {
 	return &versions, nil
 }
 
+// versions returns the requested version and all newer versions of the package of the Source
+func (c CheckRequest) versions(repository ArtifactHub) ([]Version, error) {
+	versions, err := repository.ListVersions(c.Source.pkg())
+
+	if err != nil {
+		return nil, explain(err)
+	}
+
+	if c.deprecated(versions) {
+		return []Version{c.Version}, nil
+	}
+
+	versions, err = c.Source.order(versions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err = c.Source.filter(versions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions = c.newVersions(versions)
+
+	if c.Source.TrackDependencies {
+		versions, err = c.trackDependencies(versions, repository)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return versions, nil
+}
+
 func (c CheckRequest) validate() error {
-	if len(c.Source.PackageName) == 0 || len(c.Source.RepositoryName) == 0 {
+	if len(c.Source.Packages) > 0 {
+		if err := c.Source.validatePackages(); err != nil {
+			return err
+		}
+	} else if len(c.Source.Search) > 0 {
+		if err := c.Source.validateSearch(); err != nil {
+			return err
+		}
+	} else if c.Source.catalog() {
+		if err := c.Source.validateCatalog(); err != nil {
+			return err
+		}
+	} else if len(c.Source.PackageName) == 0 || len(c.Source.RepositoryName) == 0 {
 		return fmt.Errorf(
 			"package name: %s or repository name: %s should not be empty",
 			c.Source.PackageName,
 			c.Source.RepositoryName,
 		)
 	}
+
+	if err := c.Source.validateApiKey(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.kind(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.ClientOptions(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.constraint(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.preReleasePolicy(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.minAge(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.orderBy(); err != nil {
+		return err
+	}
+
+	if _, err := c.Source.invalidVersionsStrategy(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deprecated returns true if the requested version is deprecated and would be dropped by the filter.
+// Falling back to an older version would roll back downstream jobs, so the requested version is kept instead.
+func (c CheckRequest) deprecated(versions []Version) bool {
+	if len(c.Version.Version) == 0 || c.Source.IncludeDeprecated {
+		return false
+	}
+
+	for _, version := range versions {
+		if version.Version == c.Version.Version {
+			return version.Deprecated
+		}
+	}
+
+	return false
+}
+
+// newVersions returns the requested version followed by all newer versions.
+// The given versions are expected to be in ascending order.
+func (c CheckRequest) newVersions(versions []Version) []Version {
+	if len(versions) == 0 {
+		return []Version{}
+	}
+
+	latest := versions[len(versions)-1:]
+
+	if len(c.Version.Version) == 0 {
+		return latest
+	}
+
+	for i, version := range versions {
+		if version.Version == c.Version.Version {
+			return versions[i:]
+		}
+	}
+
+	return latest
+}
+
+// validateApiKey checks that an api key id is always given together with its secret
+func (s Source) validateApiKey() error {
+	if (len(s.ApiKeyID) == 0) != (len(s.ApiKeySecret) == 0) {
+		return fmt.Errorf("api_key_id and api_key_secret have to be given together")
+	}
 	return nil
 }
 
+// ClientOptions returns the options for NewArtifactHubClient configured by the Source
+func (s Source) ClientOptions() ([]ClientOption, error) {
+	var options []ClientOption
+
+	if len(s.MaxRetryWait) > 0 {
+		maxRetryWait, err := time.ParseDuration(s.MaxRetryWait)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_retry_wait %s: %s", s.MaxRetryWait, err)
+		}
+
+		options = append(options, WithMaxRetryWait(maxRetryWait))
+	}
+
+	if len(s.CacheDir) > 0 {
+		options = append(options, WithCacheDir(s.CacheDir))
+	}
+
+	if len(s.BaseURL) > 0 {
+		if _, err := url.ParseRequestURI(s.BaseURL); err != nil {
+			return nil, fmt.Errorf("invalid base_url %s: %s", s.BaseURL, err)
+		}
+
+		options = append(options, WithBaseURL(s.BaseURL))
+	}
+
+	if len(s.Timeout) > 0 {
+		timeout, err := time.ParseDuration(s.Timeout)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %s: %s", s.Timeout, err)
+		}
+
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %s: the timeout is not positive", s.Timeout)
+		}
+
+		options = append(options, WithTimeout(timeout))
+	}
+
+	if len(s.ProxyURL) > 0 {
+		proxyUrl, err := url.ParseRequestURI(s.ProxyURL)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy_url %s: %s", s.ProxyURL, err)
+		}
+
+		options = append(options, WithProxyURL(proxyUrl))
+	}
+
+	tlsConfig, err := s.tlsConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		options = append(options, WithTLSConfig(tlsConfig))
+	}
+
+	return options, nil
+}
+
+// pkg returns the Package the Source refers to
+func (s Source) pkg() Package {
+	kind, _ := s.kind()
+
+	return Package{
+		Kind:           kind,
+		RepositoryName: s.RepositoryName,
+		PackageName:    s.PackageName,
+		ApiKey:         s.ApiKey,
+		ApiKeyID:       s.ApiKeyID,
+		ApiKeySecret:   s.ApiKeySecret,
+	}
+}
+
 // CheckRequest contains the information for the desired Source and Version
 type CheckRequest struct {
 	Source  Source  `json:"source"`
 	Version Version `json:"version"`
 }
 
-// Source contains information for the helm repository and chart package
+// Source contains information for the repository and package
 type Source struct {
-	RepositoryName string `json:"repository_name"`
-	PackageName    string `json:"package_name"`
-	ApiKey         string `json:"api_key"`
+	Kind               string          `json:"kind"`
+	RepositoryName     string          `json:"repository_name"`
+	PackageName        string          `json:"package_name"`
+	ApiKey             string          `json:"api_key"`
+	ApiKeyID           string          `json:"api_key_id"`
+	ApiKeySecret       string          `json:"api_key_secret"`
+	VersionConstraint  string          `json:"version_constraint"`
+	PreReleases        string          `json:"pre_releases"`
+	InvalidVersions    string          `json:"invalid_versions"`
+	OrderBy            string          `json:"order_by"`
+	Keyring            string          `json:"keyring"`
+	RequireSignature   bool            `json:"require_signature"`
+	MaxRetryWait       string          `json:"max_retry_wait"`
+	IncludeDeprecated  bool            `json:"include_deprecated"`
+	MinAge             string          `json:"min_age"`
+	TrackDependencies  bool            `json:"track_dependencies"`
+	Packages           []PackageSource `json:"packages"`
+	Organization       string          `json:"organization"`
+	Search             string          `json:"search"`
+	VerifiedPublisher  bool            `json:"verified_publisher"`
+	Official           bool            `json:"official"`
+	CacheDir           string          `json:"cache_dir"`
+	BaseURL            string          `json:"base_url"`
+	CACert             string          `json:"ca_cert"`
+	ClientCert         string          `json:"client_cert"`
+	ClientKey          string          `json:"client_key"`
+	InsecureSkipVerify bool            `json:"insecure_skip_verify"`
+	Timeout            string          `json:"timeout"`
+	ProxyURL           string          `json:"proxy_url"`
 }
@@ -1,11 +1,13 @@
 package resource_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"net/http"
 	"time"
 )
 
@@ -21,6 +23,31 @@ var _ = Describe("ArtifacthubResource Check", func() {
 		checkRequest = createCheckRequest("acme-charts", "my-package-name", "some-fake-api-key")
 	})
 
+	When("check is called with invalid client options", func() {
+
+		testdata := []struct {
+			description string
+			configure   func(source *resource.Source)
+		}{
+			{description: "should return an error when the timeout is invalid", configure: func(source *resource.Source) { source.Timeout = "10" }},
+			{description: "should return an error when the timeout is not positive", configure: func(source *resource.Source) { source.Timeout = "0s" }},
+			{description: "should return an error when the proxy url is invalid", configure: func(source *resource.Source) { source.ProxyURL = "proxy" }},
+			{description: "should return an error when the base url is invalid", configure: func(source *resource.Source) { source.BaseURL = "artifacthub" }},
+			{description: "should return an error when the ca cert is invalid", configure: func(source *resource.Source) { source.CACert = "no certificate" }},
+			{description: "should return an error when the client cert is given without a key", configure: func(source *resource.Source) { source.ClientCert = "some-cert" }},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(data.description, func() {
+				data.configure(&checkRequest.Source)
+				test(checkRequest, artifacthub)
+				Expect(artifacthub.ListVersionsCallCount()).To(Equal(0))
+			})
+		}
+
+	})
+
 	When("check is called with missing parameters", func() {
 
 		testdata := []struct {
@@ -30,7 +57,6 @@ var _ = Describe("ArtifacthubResource Check", func() {
 			apiKey         string
 		}{
 			{description: "should return an error when package name and repository name are empty", repositoryName: "", packageName: "", apiKey: ""},
-			{description: "should return an error when package name is empty", repositoryName: "acme-charts", packageName: "", apiKey: ""},
 			{description: "should return an error when repository name is empty", repositoryName: "", packageName: "my-package-name", apiKey: ""},
 		}
 
@@ -40,6 +66,11 @@ var _ = Describe("ArtifacthubResource Check", func() {
 			})
 		}
 
+		It("should return an error when the api key id is given without a secret", func() {
+			checkRequest.Source.ApiKeyID = "some-key-id"
+			test(checkRequest, artifacthub)
+		})
+
 	})
 
 	When("check is called with valid source", func() {
@@ -52,12 +83,13 @@ var _ = Describe("ArtifacthubResource Check", func() {
 				CreatedAt: time.Now(),
 			})
 
-			artifacthub.ListHelmVersionsReturns(packageVersions, nil)
+			artifacthub.ListVersionsReturns(packageVersions, nil)
 
 			check, err := resource.Check(checkRequest, artifacthub)
 
-			Expect(artifacthub.ListHelmVersionsCallCount()).To(Equal(1))
-			Expect(artifacthub.ListHelmVersionsArgsForCall(0)).To(Equal(resource.Package{
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(1))
+			Expect(artifacthub.ListVersionsArgsForCall(0)).To(Equal(resource.Package{
+				Kind:           "helm",
 				RepositoryName: "acme-charts",
 				PackageName:    "my-package-name",
 				ApiKey:         "some-fake-api-key",
@@ -68,15 +100,812 @@ var _ = Describe("ArtifacthubResource Check", func() {
 		})
 
 	})
+	When("check is called with an api key id and secret", func() {
+
+		It("should call list versions with the api key id and secret", func() {
+			checkRequest.Source.ApiKeyID = "some-key-id"
+			checkRequest.Source.ApiKeySecret = "some-key-secret"
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ListVersionsArgsForCall(0)).To(Equal(resource.Package{
+				Kind:           "helm",
+				RepositoryName: "acme-charts",
+				PackageName:    "my-package-name",
+				ApiKey:         "some-fake-api-key",
+				ApiKeyID:       "some-key-id",
+				ApiKeySecret:   "some-key-secret",
+			}))
+		})
+
+	})
+
+	When("check is called with a package kind", func() {
+
+		It("should call list versions with the kind", func() {
+			checkRequest.Source.Kind = "falco"
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ListVersionsArgsForCall(0).Kind).To(Equal("falco"))
+		})
+
+		It("should return an error when the kind is unknown", func() {
+			checkRequest.Source.Kind = "unknown"
+			test(checkRequest, artifacthub)
+		})
+
+	})
+
+	When("check is called with a version", func() {
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.1.5"},
+				{Version: "9.2.0"},
+				{Version: "9.2.4"},
+			}, nil)
+		})
+
+		It("should return only the latest version when no version is given", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.4"}}))
+		})
+
+		It("should return the given version and all newer versions", func() {
+			checkRequest.Version = resource.Version{Version: "9.2.0"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.0"}, {Version: "9.2.4"}}))
+		})
+
+		It("should return only the given version when it is the latest", func() {
+			checkRequest.Version = resource.Version{Version: "9.2.4"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.4"}}))
+		})
+
+		It("should return the latest version when the given version does not exist anymore", func() {
+			checkRequest.Version = resource.Version{Version: "9.1.9"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.4"}}))
+		})
+
+		It("should return an empty list when no versions are available", func() {
+			artifacthub.ListVersionsReturns(nil, nil)
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(BeEmpty())
+		})
+
+	})
+
+	When("check is called with a version constraint", func() {
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.1.5"},
+				{Version: "9.2.0"},
+				{Version: "9.2.4"},
+				{Version: "10.0.0"},
+			}, nil)
+		})
+
+		It("should only return versions matching the constraint", func() {
+			checkRequest.Source.VersionConstraint = ">=9.2.0 <10.0.0"
+			checkRequest.Version = resource.Version{Version: "9.2.0"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.0"}, {Version: "9.2.4"}}))
+		})
+
+		It("should return the latest version matching the constraint on the first check", func() {
+			checkRequest.Source.VersionConstraint = "~9.1"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.1.5"}}))
+		})
+
+		It("should return an error when the constraint is invalid", func() {
+			checkRequest.Source.VersionConstraint = "not a constraint"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(check).To(BeNil())
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(0))
+		})
+
+	})
+
+	When("check is called with a pre-release policy", func() {
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.0"},
+				{Version: "9.2.4"},
+				{Version: "10.0.0-rc.1"},
+				{Version: "10.0.0-rc.2"},
+			}, nil)
+			checkRequest.Version = resource.Version{Version: "9.2.0"}
+		})
+
+		It("should include pre-releases by default", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(4))
+		})
+
+		It("should exclude pre-releases", func() {
+			checkRequest.Source.PreReleases = "exclude"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.0"}, {Version: "9.2.4"}}))
+		})
+
+		It("should only return pre-releases", func() {
+			checkRequest.Source.PreReleases = "only"
+			checkRequest.Version = resource.Version{Version: "10.0.0-rc.1"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "10.0.0-rc.1"}, {Version: "10.0.0-rc.2"}}))
+		})
+
+		It("should match pre-releases against the version constraint", func() {
+			checkRequest.Source.PreReleases = "only"
+			checkRequest.Source.VersionConstraint = "~10"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{{Version: "10.0.0-rc.2"}}))
+		})
+
+		It("should return an error when the policy is invalid", func() {
+			checkRequest.Source.PreReleases = "sometimes"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(check).To(BeNil())
+		})
+
+	})
+
+	When("check is called with versions which are not valid semver", func() {
+
+		var day = time.Date(2020, 11, 19, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.4", CreatedAt: day.Add(3 * time.Hour)},
+				{Version: "latest", CreatedAt: day.Add(4 * time.Hour)},
+				{Version: "9.1.5", CreatedAt: day.Add(1 * time.Hour)},
+				{Version: "9.2.0", CreatedAt: day.Add(2 * time.Hour)},
+				{Version: "build-1", CreatedAt: day},
+			}, nil)
+			checkRequest.Version = resource.Version{Version: "9.1.5", CreatedAt: day.Add(1 * time.Hour)}
+		})
+
+		It("should skip them by default and order by version", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"9.1.5", "9.2.0", "9.2.4"}))
+		})
+
+		It("should order them by publish time", func() {
+			checkRequest.Source.InvalidVersions = "published_at"
+			checkRequest.Version = resource.Version{Version: "build-1", CreatedAt: day}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"build-1", "9.1.5", "9.2.0", "9.2.4", "latest"}))
+		})
+
+		It("should order them as strings after all semver versions", func() {
+			checkRequest.Source.InvalidVersions = "string"
+			checkRequest.Source.OrderBy = "semver"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"9.1.5", "9.2.0", "9.2.4", "build-1", "latest"}))
+		})
+
+		It("should return an error when they are ordered as strings without order by semver", func() {
+			checkRequest.Source.InvalidVersions = "string"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError("invalid_versions string orders invalid versions after all semver versions and needs order_by semver"))
+			Expect(check).To(BeNil())
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(0))
+		})
+
+		It("should order them independent of the order of the api", func() {
+			testdata := []struct {
+				strategy string
+				versions []resource.Version
+				expected []string
+			}{
+				{
+					strategy: "published_at",
+					versions: []resource.Version{
+						{Version: "2.0.0", CreatedAt: day.Add(1 * time.Hour)},
+						{Version: "weird", CreatedAt: day.Add(3 * time.Hour)},
+						{Version: "1.0.0", CreatedAt: day.Add(5 * time.Hour)},
+					},
+					expected: []string{"weird", "1.0.0", "2.0.0"},
+				},
+				{
+					strategy: "string",
+					versions: []resource.Version{
+						{Version: "2.0.0.1"},
+						{Version: "9.0.0"},
+						{Version: "10.0.0"},
+					},
+					expected: []string{"9.0.0", "10.0.0", "2.0.0.1"},
+				},
+			}
+
+			checkRequest.Source.OrderBy = "semver"
+
+			for _, data := range testdata {
+				checkRequest.Source.InvalidVersions = data.strategy
+
+				for _, versions := range permutations(data.versions) {
+					artifacthub.ListVersionsReturns(versions, nil)
+					checkRequest.Version = resource.Version{Version: data.expected[0]}
+
+					check, err := resource.Check(checkRequest, artifacthub)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(versionNames(*check)).To(Equal(data.expected), "input %v", versionNames(versions))
+				}
+			}
+		})
+
+		It("should return an error when the strategy is invalid", func() {
+			checkRequest.Source.InvalidVersions = "panic"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(check).To(BeNil())
+		})
+
+	})
+
+	When("check is called with order by publish time", func() {
+
+		var day = time.Date(2020, 11, 19, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "4.0.0", CreatedAt: day.Add(1 * time.Hour)},
+				{Version: "3.9.12", CreatedAt: day.Add(3 * time.Hour)},
+				{Version: "4.0.1", CreatedAt: day.Add(2 * time.Hour)},
+			}, nil)
+			checkRequest.Source.OrderBy = "published_at"
+		})
+
+		It("should return the most recently published version on the first check", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"3.9.12"}))
+		})
+
+		It("should return all versions published after the given version", func() {
+			checkRequest.Version = resource.Version{Version: "4.0.0", CreatedAt: day.Add(1 * time.Hour)}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"4.0.0", "4.0.1", "3.9.12"}))
+		})
+
+		It("should return an error when the order is invalid", func() {
+			checkRequest.Source.OrderBy = "random"
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(check).To(BeNil())
+		})
+
+	})
+
+	When("check is called with deprecated versions", func() {
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.0"},
+				{Version: "9.2.4", Deprecated: true},
+				{Version: "9.2.5"},
+			}, nil)
+			checkRequest.Version = resource.Version{Version: "9.2.0"}
+		})
+
+		It("should skip deprecated versions by default", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"9.2.0", "9.2.5"}))
+		})
+
+		It("should include deprecated versions", func() {
+			checkRequest.Source.IncludeDeprecated = true
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"9.2.0", "9.2.4", "9.2.5"}))
+		})
+
+		It("should return the requested version unchanged when it was deprecated", func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.0"},
+				{Version: "9.2.4", Deprecated: true},
+			}, nil)
+			checkRequest.Version = resource.Version{Version: "9.2.4", CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{checkRequest.Version}))
+		})
+
+	})
+
+	When("check is called with a minimum age", func() {
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.0", CreatedAt: time.Now().Add(-96 * time.Hour)},
+				{Version: "9.2.4", CreatedAt: time.Now().Add(-73 * time.Hour)},
+				{Version: "9.2.5", CreatedAt: time.Now().Add(-2 * time.Hour)},
+			}, nil)
+			checkRequest.Source.MinAge = "72h"
+		})
+
+		It("should hide versions younger than the minimum age", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionNames(*check)).To(Equal([]string{"9.2.4"}))
+		})
+
+		It("should return an error when the minimum age is invalid", func() {
+			checkRequest.Source.MinAge = "3 days"
+			test(checkRequest, artifacthub)
+		})
+
+	})
+
+	When("check is called with track_dependencies", func() {
+
+		var dependencies []resource.Dependency
+
+		BeforeEach(func() {
+			artifacthub.ListVersionsReturns([]resource.Version{
+				{Version: "9.2.0"},
+				{Version: "9.2.4"},
+			}, nil)
+			dependencies = []resource.Dependency{
+				{Name: "postgresql", Version: "10.2.0", Repository: "https://charts.bitnami.com/bitnami"},
+			}
+			artifacthub.ListVersionStub = func(p resource.Package, version string) (*resource.PackageVersion, error) {
+				return &resource.PackageVersion{
+					Version: version,
+					Data:    resource.PackageData{Dependencies: dependencies},
+				}, nil
+			}
+			checkRequest.Source.TrackDependencies = true
+		})
+
+		It("should add the dependencies digest to the latest version", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Version).To(Equal("9.2.4"))
+			Expect((*check)[0].Dependencies).ToNot(BeEmpty())
+			_, version := artifacthub.ListVersionArgsForCall(0)
+			Expect(version).To(Equal("9.2.4"))
+		})
+
+		It("should emit a new version when the dependencies of the latest version change", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			checkRequest.Version = (*check)[0]
+
+			check, err = resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{checkRequest.Version}))
+
+			dependencies[0].Version = "10.3.0"
+
+			check, err = resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Version).To(Equal("9.2.4"))
+			Expect((*check)[0].Dependencies).ToNot(Equal(checkRequest.Version.Dependencies))
+		})
+
+		It("should return the requested version with its digest and add the digest to the latest version", func() {
+			checkRequest.Version = resource.Version{Version: "9.2.0", Dependencies: "some-digest"}
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(2))
+			Expect((*check)[0]).To(Equal(checkRequest.Version))
+			Expect((*check)[1].Dependencies).ToNot(BeEmpty())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(1))
+		})
+
+		It("should not modify the listed versions", func() {
+			listed := []resource.Version{{Version: "9.2.0"}, {Version: "9.2.4"}}
+			artifacthub.ListVersionsReturns(listed, nil)
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed[1].Dependencies).To(BeEmpty())
+		})
+
+	})
+
+	When("check is called with packages", func() {
+
+		var packageVersions map[string][]resource.Version
+
+		BeforeEach(func() {
+			packageVersions = map[string][]resource.Version{
+				"my-package-name": {{Version: "9.2.0"}, {Version: "9.2.4", CreatedAt: time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)}},
+				"other-package":   {{Version: "1.0.0", CreatedAt: time.Date(2020, 11, 20, 10, 0, 0, 0, time.UTC)}},
+			}
+			artifacthub.ListVersionsStub = func(p resource.Package) ([]resource.Version, error) {
+				return packageVersions[p.PackageName], nil
+			}
+			checkRequest.Source.RepositoryName = ""
+			checkRequest.Source.PackageName = ""
+			checkRequest.Source.Packages = []resource.PackageSource{
+				{RepositoryName: "acme-charts", PackageName: "my-package-name"},
+				{RepositoryName: "other-charts", PackageName: "other-package"},
+			}
+		})
+
+		It("should return a composite version of the latest versions", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Manifest).To(Equal("acme-charts/my-package-name@9.2.4,other-charts/other-package@1.0.0"))
+			Expect((*check)[0].Version).To(HaveLen(16))
+			Expect((*check)[0].CreatedAt).To(Equal(time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)))
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(2))
+		})
+
+		It("should list the versions with the credentials of the source", func() {
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]resource.Package{artifacthub.ListVersionsArgsForCall(0), artifacthub.ListVersionsArgsForCall(1)}).To(ConsistOf(
+				resource.Package{Kind: "helm", RepositoryName: "acme-charts", PackageName: "my-package-name", ApiKey: "some-fake-api-key"},
+				resource.Package{Kind: "helm", RepositoryName: "other-charts", PackageName: "other-package", ApiKey: "some-fake-api-key"},
+			))
+		})
+
+		It("should return the requested version when no package has a new version", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			checkRequest.Version = (*check)[0]
+
+			check, err = resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{checkRequest.Version}))
+		})
+
+		It("should return a new composite version when a package has a new version", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			checkRequest.Version = (*check)[0]
+
+			packageVersions["other-package"] = append(packageVersions["other-package"], resource.Version{Version: "1.1.0"})
+			check, err = resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Version).ToNot(Equal(checkRequest.Version.Version))
+			Expect((*check)[0].Manifest).To(Equal("acme-charts/my-package-name@9.2.4,other-charts/other-package@1.1.0"))
+			Expect((*check)[0].Changes).To(Equal("other-charts/other-package@1.1.0"))
+		})
+
+		It("should return an error when a package has no versions", func() {
+			packageVersions["other-package"] = nil
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("no version found for package other-charts/other-package")))
+		})
+
+		It("should return an error when a package is given more than once", func() {
+			checkRequest.Source.Packages = append(checkRequest.Source.Packages, checkRequest.Source.Packages[0])
+			test(checkRequest, artifacthub)
+		})
+
+		It("should return an error when packages are given together with a package name", func() {
+			checkRequest.Source.PackageName = "my-package-name"
+			test(checkRequest, artifacthub)
+		})
+
+		It("should return an error when packages are given together with a search", func() {
+			checkRequest.Source.Search = "my-package"
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError("packages can not be given together with repository_name, package_name, organization or search"))
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(0))
+		})
+
+	})
+
+	When("check is called with a repository only", func() {
+
+		var results []resource.SearchResult
+
+		BeforeEach(func() {
+			checkRequest.Source.PackageName = ""
+			results = []resource.SearchResult{
+				{Name: "my-package-name", Version: "9.2.4", TS: resource.Epoch(time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)), Repository: resource.Repository{Name: "acme-charts"}},
+				{Name: "other-package", Version: "1.0.0", Repository: resource.Repository{Name: "acme-charts"}},
+				{Name: "old-package", Version: "0.1.0", Deprecated: true, Repository: resource.Repository{Name: "acme-charts"}},
+			}
+			artifacthub.SearchPackagesStub = func(p resource.Package, query resource.SearchQuery) ([]resource.SearchResult, error) {
+				return results, nil
+			}
+		})
+
+		It("should search the packages of the repository", func() {
+			checkRequest.Source.Kind = "olm"
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(1))
+			p, query := artifacthub.SearchPackagesArgsForCall(0)
+			Expect(p.ApiKey).To(Equal("some-fake-api-key"))
+			Expect(query).To(Equal(resource.SearchQuery{Repository: "acme-charts", Kind: "olm"}))
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(0))
+		})
+
+		It("should return a digest of all packages which are not deprecated without the manifest", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Version).To(HaveLen(16))
+			Expect((*check)[0].Manifest).To(BeEmpty())
+			Expect((*check)[0].CreatedAt).To(Equal(time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)))
+
+			results[2].Deprecated = false
+			withDeprecated, err := resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect((*withDeprecated)[0].Version).ToNot(Equal((*check)[0].Version))
+		})
+
+		It("should return a new version with the packages published since the previous version", func() {
+			check, err := resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect((*check)[0].Previous).To(BeEmpty())
+			Expect((*check)[0].Changes).To(BeEmpty())
+			checkRequest.Version = (*check)[0]
+
+			check, err = resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(Equal([]resource.Version{checkRequest.Version}))
+
+			results[1].Version = "1.1.0"
+			results[1].TS = resource.Epoch(time.Date(2020, 11, 26, 10, 0, 0, 0, time.UTC))
+			results = append(results, resource.SearchResult{
+				Name:       "new-package",
+				Version:    "0.0.1",
+				TS:         resource.Epoch(time.Date(2020, 11, 27, 10, 0, 0, 0, time.UTC)),
+				Repository: resource.Repository{Name: "acme-charts"},
+			})
+
+			check, err = resource.Check(checkRequest, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*check).To(HaveLen(1))
+			Expect((*check)[0].Version).ToNot(Equal(checkRequest.Version.Version))
+			Expect((*check)[0].Previous).To(Equal(checkRequest.Version.Version))
+			Expect((*check)[0].Changes).To(Equal("acme-charts/new-package@0.0.1,acme-charts/other-package@1.1.0"))
+			Expect((*check)[0].CreatedAt).To(Equal(time.Date(2020, 11, 27, 10, 0, 0, 0, time.UTC)))
+		})
+
+		It("should search the packages of an organization", func() {
+			checkRequest.Source.RepositoryName = ""
+			checkRequest.Source.Organization = "acme"
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, query := artifacthub.SearchPackagesArgsForCall(0)
+			Expect(query).To(Equal(resource.SearchQuery{Organization: "acme"}))
+		})
+
+		It("should return an error when no packages are found", func() {
+			results = nil
+			test(checkRequest, artifacthub)
+		})
+
+		It("should return an error when a version constraint is given", func() {
+			checkRequest.Source.VersionConstraint = "~9.2"
+			test(checkRequest, artifacthub)
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(0))
+		})
+
+	})
+
+	When("check is called with a search", func() {
+
+		var results []resource.SearchResult
+
+		BeforeEach(func() {
+			checkRequest.Source.RepositoryName = ""
+			checkRequest.Source.PackageName = ""
+			checkRequest.Source.Search = "my-package-name"
+			results = []resource.SearchResult{
+				{Name: "my-package-name-operator", Repository: resource.Repository{Name: "acme-operators", Kind: 3}},
+				{Name: "my-package-name", Repository: resource.Repository{Name: "acme-charts", Kind: 0}},
+			}
+			artifacthub.SearchPackagesStub = func(p resource.Package, query resource.SearchQuery) ([]resource.SearchResult, error) {
+				return results, nil
+			}
+			artifacthub.ListVersionsReturns([]resource.Version{{Version: "9.2.4"}}, nil)
+		})
+
+		It("should search with the given filters", func() {
+			checkRequest.Source.Kind = "helm"
+			checkRequest.Source.VerifiedPublisher = true
+			checkRequest.Source.Official = true
+			results = results[1:]
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, query := artifacthub.SearchPackagesArgsForCall(0)
+			Expect(query).To(Equal(resource.SearchQuery{
+				Text:              "my-package-name",
+				Kind:              "helm",
+				VerifiedPublisher: true,
+				Official:          true,
+				Limit:             11,
+			}))
+		})
+
+		It("should list the versions of the single package found and pin the package in the versions", func() {
+			results = results[:1]
+
+			check, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ListVersionsArgsForCall(0)).To(Equal(resource.Package{
+				Kind:           "olm",
+				RepositoryName: "acme-operators",
+				PackageName:    "my-package-name-operator",
+				ApiKey:         "some-fake-api-key",
+			}))
+			Expect(*check).To(Equal([]resource.Version{{Version: "9.2.4", Package: "olm/acme-operators/my-package-name-operator"}}))
+		})
+
+		It("should return an error with the candidates even if a package is named exactly like the search", func() {
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("candidates are: acme-operators/my-package-name-operator (olm), acme-charts/my-package-name (helm)")))
+			Expect(artifacthub.ListVersionsCallCount()).To(Equal(0))
+		})
+
+		It("should list at most ten candidates", func() {
+			results = nil
+
+			for i := 0; i < 11; i++ {
+				results = append(results, resource.SearchResult{Name: fmt.Sprintf("package-%d", i), Repository: resource.Repository{Name: "acme-charts"}})
+			}
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("acme-charts/package-9 (helm), and more")))
+			Expect(err).ToNot(MatchError(ContainSubstring("package-10")))
+		})
+
+		It("should return an error when no package is found", func() {
+			results = nil
+
+			_, err := resource.Check(checkRequest, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("no package found for search my-package-name")))
+		})
+
+		It("should return an error when a package name is given", func() {
+			checkRequest.Source.PackageName = "my-package-name"
+			test(checkRequest, artifacthub)
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(0))
+		})
+
+	})
+
 	When("list versions fails", func() {
 
 		It("should return an error when call to list versions failed", func() {
-			artifacthub.ListHelmVersionsReturns(nil, fmt.Errorf("some error occurred"))
+			artifacthub.ListVersionsReturns(nil, fmt.Errorf("some error occurred"))
 			check, err := resource.Check(checkRequest, artifacthub)
 			Expect(err).To(HaveOccurred())
 			Expect(check).To(BeNil())
 		})
 
+		testdata := []struct {
+			statusCode  int
+			credentials string
+			advice      string
+		}{
+			{statusCode: http.StatusNotFound, advice: "check that repository_name, package_name and the version exist"},
+			{statusCode: http.StatusUnauthorized, credentials: "api_key_id", advice: "check that api_key_id and api_key_secret are valid"},
+			{statusCode: http.StatusUnauthorized, credentials: "api_key", advice: "check that api_key is valid"},
+			{statusCode: http.StatusForbidden, advice: "no api key was sent"},
+			{statusCode: http.StatusTooManyRequests, advice: "rate limit is exceeded"},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(fmt.Sprintf("should explain an api error with status code %d and credentials %q", data.statusCode, data.credentials), func() {
+				artifacthub.ListVersionsReturns(nil, &resource.APIError{
+					StatusCode:  data.statusCode,
+					URL:         "https://artifacthub.io/api/v1/packages/helm/acme-charts/my-package-name",
+					Message:     "some message",
+					Credentials: data.credentials,
+				})
+
+				check, err := resource.Check(checkRequest, artifacthub)
+
+				Expect(check).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring(data.advice)))
+				Expect(err).To(MatchError(ContainSubstring("some message")))
+
+				var apiError *resource.APIError
+				Expect(errors.As(err, &apiError)).To(BeTrue())
+				Expect(apiError.StatusCode).To(Equal(data.statusCode))
+			})
+		}
+
 	})
 })
 
@@ -95,3 +924,29 @@ func createCheckRequest(repositoryName string, packageName string, apiKey string
 		},
 	}
 }
+
+func versionNames(versions []resource.Version) []string {
+	var names []string
+	for _, version := range versions {
+		names = append(names, version.Version)
+	}
+	return names
+}
+
+func permutations(versions []resource.Version) [][]resource.Version {
+	if len(versions) <= 1 {
+		return [][]resource.Version{versions}
+	}
+
+	var result [][]resource.Version
+
+	for i := range versions {
+		rest := append(append([]resource.Version{}, versions[:i]...), versions[i+1:]...)
+
+		for _, permutation := range permutations(rest) {
+			result = append(result, append([]resource.Version{versions[i]}, permutation...))
+		}
+	}
+
+	return result
+}
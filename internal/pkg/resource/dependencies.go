@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PackageData contains the kind specific data of a PackageVersion
+type PackageData struct {
+	Dependencies []Dependency `json:"dependencies"`
+	KubeVersion  string       `json:"kubeVersion"`
+}
+
+// Dependency represents a sub-chart dependency of a helm chart
+type Dependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+}
+
+// trackDependencies returns a copy of the versions with the digest of the dependencies of the latest version
+// added to the latest version, so a change of the dependencies results in a new version.
+// The requested version keeps its digest, so it is returned with the same identity.
+func (c CheckRequest) trackDependencies(versions []Version, repository ArtifactHub) ([]Version, error) {
+	if len(versions) == 0 {
+		return versions, nil
+	}
+
+	versions = append([]Version{}, versions...)
+
+	for i := range versions {
+		if versions[i].Version == c.Version.Version {
+			versions[i].Dependencies = c.Version.Dependencies
+		}
+	}
+
+	latest := &versions[len(versions)-1]
+	version, err := repository.ListVersion(c.Source.pkg(), latest.Version)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies of version %s: %w", latest.Version, explain(err))
+	}
+
+	latest.Dependencies = dependenciesDigest(version.Data.Dependencies)
+
+	return versions, nil
+}
+
+// dependenciesDigest returns a short digest of the dependencies which does not depend on their order
+func dependenciesDigest(dependencies []Dependency) string {
+	var lines []string
+
+	for _, dependency := range dependencies {
+		lines = append(lines, fmt.Sprintf("%s@%s %s", dependency.Name, dependency.Version, dependency.Repository))
+	}
+
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// dependenciesJSON returns the dependencies of the version as JSON array
+func dependenciesJSON(version *PackageVersion) (string, error) {
+	dependencies := version.Data.Dependencies
+
+	if dependencies == nil {
+		dependencies = []Dependency{}
+	}
+
+	content, err := json.MarshalIndent(dependencies, "", "  ")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dependencies: %s", err)
+	}
+
+	return string(content), nil
+}
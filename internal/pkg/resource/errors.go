@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize limits how much of an error response body is read
+const maxErrorBodySize = 4096
+
+// APIError is returned when artifacthub responds with an unexpected status code
+type APIError struct {
+	StatusCode int
+	URL        string
+	RequestID  string
+	Message    string
+	// Credentials is the source parameter of the credentials sent with the request, if any
+	Credentials string
+}
+
+// Error returns the status code, url, request id and message of the APIError
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("request to %s returned status code: %d", e.URL, e.StatusCode)
+
+	if len(e.RequestID) > 0 {
+		message = fmt.Sprintf("%s (request id: %s)", message, e.RequestID)
+	}
+
+	if len(e.Message) > 0 {
+		message = fmt.Sprintf("%s with message: %s", message, e.Message)
+	}
+
+	return message
+}
+
+// newAPIError creates an APIError from the response and its body.
+// The message is taken from a JSON body with a message field or from the plain body.
+func newAPIError(response *http.Response) *APIError {
+	apiError := &APIError{
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get("X-Request-Id"),
+	}
+
+	if response.Request != nil {
+		apiError.URL = response.Request.URL.String()
+		apiError.Credentials = credentials(response.Request.Header)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+
+	if err != nil {
+		return apiError
+	}
+
+	var target struct {
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(body, &target); err == nil && len(target.Message) > 0 {
+		apiError.Message = target.Message
+	} else {
+		apiError.Message = strings.TrimSpace(string(body))
+	}
+
+	return apiError
+}
+
+// explain adds advice on how to resolve an APIError
+func explain(err error) error {
+	var apiError *APIError
+
+	if !errors.As(err, &apiError) {
+		return err
+	}
+
+	switch apiError.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: check that repository_name, package_name and the version exist on artifacthub", err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		switch apiError.Credentials {
+		case "api_key":
+			return fmt.Errorf("%w: check that api_key is valid", err)
+		case "api_key_id":
+			return fmt.Errorf("%w: check that api_key_id and api_key_secret are valid", err)
+		default:
+			return fmt.Errorf("%w: no api key was sent, configure api_key_id and api_key_secret", err)
+		}
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: the artifacthub rate limit is exceeded, configure an api key or increase max_retry_wait", err)
+	default:
+		return err
+	}
+}
+
+// credentials returns the source parameter of the credentials sent with the request headers
+func credentials(header http.Header) string {
+	if len(header.Get("X-API-KEY-ID")) > 0 {
+		return "api_key_id"
+	}
+
+	if len(header.Get("Authorization")) > 0 {
+		return "api_key"
+	}
+
+	return ""
+}
@@ -0,0 +1,47 @@
+package resource
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithoutCertificates exports withoutCertificates for tests
+var WithoutCertificates = withoutCertificates
+
+// RetryAfter exports retryAfter for tests
+var RetryAfter = retryAfter
+
+// NewRetryTransport returns a retryTransport for tests
+func NewRetryTransport(transport http.RoundTripper, maxWait time.Duration, baseDelay time.Duration, maxDelay time.Duration) http.RoundTripper {
+	return &retryTransport{
+		transport: transport,
+		timeout:   time.Second,
+		maxWait:   maxWait,
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+	}
+}
+
+// Backoff exports the backoff of a retryTransport for tests
+func Backoff(baseDelay time.Duration, maxDelay time.Duration, attempt int) time.Duration {
+	return (&retryTransport{baseDelay: baseDelay, maxDelay: maxDelay}).backoff(attempt)
+}
+
+// NewCacheTransport returns a cacheTransport for tests
+func NewCacheTransport(transport http.RoundTripper, dir string, maxEntries int, maxSize int64) http.RoundTripper {
+	return &cacheTransport{
+		transport:  transport,
+		dir:        dir,
+		maxEntries: maxEntries,
+		maxSize:    maxSize,
+	}
+}
+
+// NewScopedTransport returns a scopedTransport for tests
+func NewScopedTransport(host string, transport http.RoundTripper, fallback http.RoundTripper) http.RoundTripper {
+	return &scopedTransport{
+		host:      host,
+		transport: transport,
+		fallback:  fallback,
+	}
+}
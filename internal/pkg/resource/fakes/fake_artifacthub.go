@@ -2,54 +2,140 @@
 package fakes
 
 import (
+	"io"
 	"sync"
 
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
 )
 
 type FakeArtifactHub struct {
-	ListHelmVersionStub        func(resource.Package, string) (*resource.HelmVersion, error)
-	listHelmVersionMutex       sync.RWMutex
-	listHelmVersionArgsForCall []struct {
+	ChangelogStub        func(resource.Package, string) ([]resource.ChangelogEntry, error)
+	changelogMutex       sync.RWMutex
+	changelogArgsForCall []struct {
 		arg1 resource.Package
 		arg2 string
 	}
-	listHelmVersionReturns struct {
-		result1 *resource.HelmVersion
+	changelogReturns struct {
+		result1 []resource.ChangelogEntry
 		result2 error
 	}
-	listHelmVersionReturnsOnCall map[int]struct {
-		result1 *resource.HelmVersion
+	changelogReturnsOnCall map[int]struct {
+		result1 []resource.ChangelogEntry
 		result2 error
 	}
-	ListHelmVersionsStub        func(resource.Package) ([]resource.Version, error)
-	listHelmVersionsMutex       sync.RWMutex
-	listHelmVersionsArgsForCall []struct {
+	DownloadStub        func(string, io.Writer) error
+	downloadMutex       sync.RWMutex
+	downloadArgsForCall []struct {
+		arg1 string
+		arg2 io.Writer
+	}
+	downloadReturns struct {
+		result1 error
+	}
+	downloadReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListVersionStub        func(resource.Package, string) (*resource.PackageVersion, error)
+	listVersionMutex       sync.RWMutex
+	listVersionArgsForCall []struct {
 		arg1 resource.Package
+		arg2 string
 	}
-	listHelmVersionsReturns struct {
+	listVersionReturns struct {
+		result1 *resource.PackageVersion
+		result2 error
+	}
+	listVersionReturnsOnCall map[int]struct {
+		result1 *resource.PackageVersion
+		result2 error
+	}
+	ListVersionsStub        func(resource.Package) ([]resource.Version, error)
+	listVersionsMutex       sync.RWMutex
+	listVersionsArgsForCall []struct {
+		arg1 resource.Package
+	}
+	listVersionsReturns struct {
 		result1 []resource.Version
 		result2 error
 	}
-	listHelmVersionsReturnsOnCall map[int]struct {
+	listVersionsReturnsOnCall map[int]struct {
 		result1 []resource.Version
 		result2 error
 	}
+	SearchPackagesStub        func(resource.Package, resource.SearchQuery) ([]resource.SearchResult, error)
+	searchPackagesMutex       sync.RWMutex
+	searchPackagesArgsForCall []struct {
+		arg1 resource.Package
+		arg2 resource.SearchQuery
+	}
+	searchPackagesReturns struct {
+		result1 []resource.SearchResult
+		result2 error
+	}
+	searchPackagesReturnsOnCall map[int]struct {
+		result1 []resource.SearchResult
+		result2 error
+	}
+	SecurityReportStub        func(resource.Package, string, string) ([]byte, error)
+	securityReportMutex       sync.RWMutex
+	securityReportArgsForCall []struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}
+	securityReportReturns struct {
+		result1 []byte
+		result2 error
+	}
+	securityReportReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	ValuesStub        func(resource.Package, string, string) ([]byte, error)
+	valuesMutex       sync.RWMutex
+	valuesArgsForCall []struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}
+	valuesReturns struct {
+		result1 []byte
+		result2 error
+	}
+	valuesReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	ValuesSchemaStub        func(resource.Package, string, string) ([]byte, error)
+	valuesSchemaMutex       sync.RWMutex
+	valuesSchemaArgsForCall []struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}
+	valuesSchemaReturns struct {
+		result1 []byte
+		result2 error
+	}
+	valuesSchemaReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeArtifactHub) ListHelmVersion(arg1 resource.Package, arg2 string) (*resource.HelmVersion, error) {
-	fake.listHelmVersionMutex.Lock()
-	ret, specificReturn := fake.listHelmVersionReturnsOnCall[len(fake.listHelmVersionArgsForCall)]
-	fake.listHelmVersionArgsForCall = append(fake.listHelmVersionArgsForCall, struct {
+func (fake *FakeArtifactHub) Changelog(arg1 resource.Package, arg2 string) ([]resource.ChangelogEntry, error) {
+	fake.changelogMutex.Lock()
+	ret, specificReturn := fake.changelogReturnsOnCall[len(fake.changelogArgsForCall)]
+	fake.changelogArgsForCall = append(fake.changelogArgsForCall, struct {
 		arg1 resource.Package
 		arg2 string
 	}{arg1, arg2})
-	stub := fake.ListHelmVersionStub
-	fakeReturns := fake.listHelmVersionReturns
-	fake.recordInvocation("ListHelmVersion", []interface{}{arg1, arg2})
-	fake.listHelmVersionMutex.Unlock()
+	stub := fake.ChangelogStub
+	fakeReturns := fake.changelogReturns
+	fake.recordInvocation("Changelog", []interface{}{arg1, arg2})
+	fake.changelogMutex.Unlock()
 	if stub != nil {
 		return stub(arg1, arg2)
 	}
@@ -59,61 +145,188 @@ func (fake *FakeArtifactHub) ListHelmVersion(arg1 resource.Package, arg2 string)
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionCallCount() int {
-	fake.listHelmVersionMutex.RLock()
-	defer fake.listHelmVersionMutex.RUnlock()
-	return len(fake.listHelmVersionArgsForCall)
+func (fake *FakeArtifactHub) ChangelogCallCount() int {
+	fake.changelogMutex.RLock()
+	defer fake.changelogMutex.RUnlock()
+	return len(fake.changelogArgsForCall)
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionCalls(stub func(resource.Package, string) (*resource.HelmVersion, error)) {
-	fake.listHelmVersionMutex.Lock()
-	defer fake.listHelmVersionMutex.Unlock()
-	fake.ListHelmVersionStub = stub
+func (fake *FakeArtifactHub) ChangelogCalls(stub func(resource.Package, string) ([]resource.ChangelogEntry, error)) {
+	fake.changelogMutex.Lock()
+	defer fake.changelogMutex.Unlock()
+	fake.ChangelogStub = stub
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionArgsForCall(i int) (resource.Package, string) {
-	fake.listHelmVersionMutex.RLock()
-	defer fake.listHelmVersionMutex.RUnlock()
-	argsForCall := fake.listHelmVersionArgsForCall[i]
+func (fake *FakeArtifactHub) ChangelogArgsForCall(i int) (resource.Package, string) {
+	fake.changelogMutex.RLock()
+	defer fake.changelogMutex.RUnlock()
+	argsForCall := fake.changelogArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionReturns(result1 *resource.HelmVersion, result2 error) {
-	fake.listHelmVersionMutex.Lock()
-	defer fake.listHelmVersionMutex.Unlock()
-	fake.ListHelmVersionStub = nil
-	fake.listHelmVersionReturns = struct {
-		result1 *resource.HelmVersion
+func (fake *FakeArtifactHub) ChangelogReturns(result1 []resource.ChangelogEntry, result2 error) {
+	fake.changelogMutex.Lock()
+	defer fake.changelogMutex.Unlock()
+	fake.ChangelogStub = nil
+	fake.changelogReturns = struct {
+		result1 []resource.ChangelogEntry
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionReturnsOnCall(i int, result1 *resource.HelmVersion, result2 error) {
-	fake.listHelmVersionMutex.Lock()
-	defer fake.listHelmVersionMutex.Unlock()
-	fake.ListHelmVersionStub = nil
-	if fake.listHelmVersionReturnsOnCall == nil {
-		fake.listHelmVersionReturnsOnCall = make(map[int]struct {
-			result1 *resource.HelmVersion
+func (fake *FakeArtifactHub) ChangelogReturnsOnCall(i int, result1 []resource.ChangelogEntry, result2 error) {
+	fake.changelogMutex.Lock()
+	defer fake.changelogMutex.Unlock()
+	fake.ChangelogStub = nil
+	if fake.changelogReturnsOnCall == nil {
+		fake.changelogReturnsOnCall = make(map[int]struct {
+			result1 []resource.ChangelogEntry
 			result2 error
 		})
 	}
-	fake.listHelmVersionReturnsOnCall[i] = struct {
-		result1 *resource.HelmVersion
+	fake.changelogReturnsOnCall[i] = struct {
+		result1 []resource.ChangelogEntry
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeArtifactHub) ListHelmVersions(arg1 resource.Package) ([]resource.Version, error) {
-	fake.listHelmVersionsMutex.Lock()
-	ret, specificReturn := fake.listHelmVersionsReturnsOnCall[len(fake.listHelmVersionsArgsForCall)]
-	fake.listHelmVersionsArgsForCall = append(fake.listHelmVersionsArgsForCall, struct {
+func (fake *FakeArtifactHub) Download(arg1 string, arg2 io.Writer) error {
+	fake.downloadMutex.Lock()
+	ret, specificReturn := fake.downloadReturnsOnCall[len(fake.downloadArgsForCall)]
+	fake.downloadArgsForCall = append(fake.downloadArgsForCall, struct {
+		arg1 string
+		arg2 io.Writer
+	}{arg1, arg2})
+	stub := fake.DownloadStub
+	fakeReturns := fake.downloadReturns
+	fake.recordInvocation("Download", []interface{}{arg1, arg2})
+	fake.downloadMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeArtifactHub) DownloadCallCount() int {
+	fake.downloadMutex.RLock()
+	defer fake.downloadMutex.RUnlock()
+	return len(fake.downloadArgsForCall)
+}
+
+func (fake *FakeArtifactHub) DownloadCalls(stub func(string, io.Writer) error) {
+	fake.downloadMutex.Lock()
+	defer fake.downloadMutex.Unlock()
+	fake.DownloadStub = stub
+}
+
+func (fake *FakeArtifactHub) DownloadArgsForCall(i int) (string, io.Writer) {
+	fake.downloadMutex.RLock()
+	defer fake.downloadMutex.RUnlock()
+	argsForCall := fake.downloadArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeArtifactHub) DownloadReturns(result1 error) {
+	fake.downloadMutex.Lock()
+	defer fake.downloadMutex.Unlock()
+	fake.DownloadStub = nil
+	fake.downloadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeArtifactHub) DownloadReturnsOnCall(i int, result1 error) {
+	fake.downloadMutex.Lock()
+	defer fake.downloadMutex.Unlock()
+	fake.DownloadStub = nil
+	if fake.downloadReturnsOnCall == nil {
+		fake.downloadReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.downloadReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeArtifactHub) ListVersion(arg1 resource.Package, arg2 string) (*resource.PackageVersion, error) {
+	fake.listVersionMutex.Lock()
+	ret, specificReturn := fake.listVersionReturnsOnCall[len(fake.listVersionArgsForCall)]
+	fake.listVersionArgsForCall = append(fake.listVersionArgsForCall, struct {
+		arg1 resource.Package
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListVersionStub
+	fakeReturns := fake.listVersionReturns
+	fake.recordInvocation("ListVersion", []interface{}{arg1, arg2})
+	fake.listVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeArtifactHub) ListVersionCallCount() int {
+	fake.listVersionMutex.RLock()
+	defer fake.listVersionMutex.RUnlock()
+	return len(fake.listVersionArgsForCall)
+}
+
+func (fake *FakeArtifactHub) ListVersionCalls(stub func(resource.Package, string) (*resource.PackageVersion, error)) {
+	fake.listVersionMutex.Lock()
+	defer fake.listVersionMutex.Unlock()
+	fake.ListVersionStub = stub
+}
+
+func (fake *FakeArtifactHub) ListVersionArgsForCall(i int) (resource.Package, string) {
+	fake.listVersionMutex.RLock()
+	defer fake.listVersionMutex.RUnlock()
+	argsForCall := fake.listVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeArtifactHub) ListVersionReturns(result1 *resource.PackageVersion, result2 error) {
+	fake.listVersionMutex.Lock()
+	defer fake.listVersionMutex.Unlock()
+	fake.ListVersionStub = nil
+	fake.listVersionReturns = struct {
+		result1 *resource.PackageVersion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) ListVersionReturnsOnCall(i int, result1 *resource.PackageVersion, result2 error) {
+	fake.listVersionMutex.Lock()
+	defer fake.listVersionMutex.Unlock()
+	fake.ListVersionStub = nil
+	if fake.listVersionReturnsOnCall == nil {
+		fake.listVersionReturnsOnCall = make(map[int]struct {
+			result1 *resource.PackageVersion
+			result2 error
+		})
+	}
+	fake.listVersionReturnsOnCall[i] = struct {
+		result1 *resource.PackageVersion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) ListVersions(arg1 resource.Package) ([]resource.Version, error) {
+	fake.listVersionsMutex.Lock()
+	ret, specificReturn := fake.listVersionsReturnsOnCall[len(fake.listVersionsArgsForCall)]
+	fake.listVersionsArgsForCall = append(fake.listVersionsArgsForCall, struct {
 		arg1 resource.Package
 	}{arg1})
-	stub := fake.ListHelmVersionsStub
-	fakeReturns := fake.listHelmVersionsReturns
-	fake.recordInvocation("ListHelmVersions", []interface{}{arg1})
-	fake.listHelmVersionsMutex.Unlock()
+	stub := fake.ListVersionsStub
+	fakeReturns := fake.listVersionsReturns
+	fake.recordInvocation("ListVersions", []interface{}{arg1})
+	fake.listVersionsMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
@@ -123,58 +336,333 @@ func (fake *FakeArtifactHub) ListHelmVersions(arg1 resource.Package) ([]resource
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionsCallCount() int {
-	fake.listHelmVersionsMutex.RLock()
-	defer fake.listHelmVersionsMutex.RUnlock()
-	return len(fake.listHelmVersionsArgsForCall)
+func (fake *FakeArtifactHub) ListVersionsCallCount() int {
+	fake.listVersionsMutex.RLock()
+	defer fake.listVersionsMutex.RUnlock()
+	return len(fake.listVersionsArgsForCall)
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionsCalls(stub func(resource.Package) ([]resource.Version, error)) {
-	fake.listHelmVersionsMutex.Lock()
-	defer fake.listHelmVersionsMutex.Unlock()
-	fake.ListHelmVersionsStub = stub
+func (fake *FakeArtifactHub) ListVersionsCalls(stub func(resource.Package) ([]resource.Version, error)) {
+	fake.listVersionsMutex.Lock()
+	defer fake.listVersionsMutex.Unlock()
+	fake.ListVersionsStub = stub
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionsArgsForCall(i int) resource.Package {
-	fake.listHelmVersionsMutex.RLock()
-	defer fake.listHelmVersionsMutex.RUnlock()
-	argsForCall := fake.listHelmVersionsArgsForCall[i]
+func (fake *FakeArtifactHub) ListVersionsArgsForCall(i int) resource.Package {
+	fake.listVersionsMutex.RLock()
+	defer fake.listVersionsMutex.RUnlock()
+	argsForCall := fake.listVersionsArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionsReturns(result1 []resource.Version, result2 error) {
-	fake.listHelmVersionsMutex.Lock()
-	defer fake.listHelmVersionsMutex.Unlock()
-	fake.ListHelmVersionsStub = nil
-	fake.listHelmVersionsReturns = struct {
+func (fake *FakeArtifactHub) ListVersionsReturns(result1 []resource.Version, result2 error) {
+	fake.listVersionsMutex.Lock()
+	defer fake.listVersionsMutex.Unlock()
+	fake.ListVersionsStub = nil
+	fake.listVersionsReturns = struct {
 		result1 []resource.Version
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeArtifactHub) ListHelmVersionsReturnsOnCall(i int, result1 []resource.Version, result2 error) {
-	fake.listHelmVersionsMutex.Lock()
-	defer fake.listHelmVersionsMutex.Unlock()
-	fake.ListHelmVersionsStub = nil
-	if fake.listHelmVersionsReturnsOnCall == nil {
-		fake.listHelmVersionsReturnsOnCall = make(map[int]struct {
+func (fake *FakeArtifactHub) ListVersionsReturnsOnCall(i int, result1 []resource.Version, result2 error) {
+	fake.listVersionsMutex.Lock()
+	defer fake.listVersionsMutex.Unlock()
+	fake.ListVersionsStub = nil
+	if fake.listVersionsReturnsOnCall == nil {
+		fake.listVersionsReturnsOnCall = make(map[int]struct {
 			result1 []resource.Version
 			result2 error
 		})
 	}
-	fake.listHelmVersionsReturnsOnCall[i] = struct {
+	fake.listVersionsReturnsOnCall[i] = struct {
 		result1 []resource.Version
 		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeArtifactHub) SearchPackages(arg1 resource.Package, arg2 resource.SearchQuery) ([]resource.SearchResult, error) {
+	fake.searchPackagesMutex.Lock()
+	ret, specificReturn := fake.searchPackagesReturnsOnCall[len(fake.searchPackagesArgsForCall)]
+	fake.searchPackagesArgsForCall = append(fake.searchPackagesArgsForCall, struct {
+		arg1 resource.Package
+		arg2 resource.SearchQuery
+	}{arg1, arg2})
+	stub := fake.SearchPackagesStub
+	fakeReturns := fake.searchPackagesReturns
+	fake.recordInvocation("SearchPackages", []interface{}{arg1, arg2})
+	fake.searchPackagesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeArtifactHub) SearchPackagesCallCount() int {
+	fake.searchPackagesMutex.RLock()
+	defer fake.searchPackagesMutex.RUnlock()
+	return len(fake.searchPackagesArgsForCall)
+}
+
+func (fake *FakeArtifactHub) SearchPackagesCalls(stub func(resource.Package, resource.SearchQuery) ([]resource.SearchResult, error)) {
+	fake.searchPackagesMutex.Lock()
+	defer fake.searchPackagesMutex.Unlock()
+	fake.SearchPackagesStub = stub
+}
+
+func (fake *FakeArtifactHub) SearchPackagesArgsForCall(i int) (resource.Package, resource.SearchQuery) {
+	fake.searchPackagesMutex.RLock()
+	defer fake.searchPackagesMutex.RUnlock()
+	argsForCall := fake.searchPackagesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeArtifactHub) SearchPackagesReturns(result1 []resource.SearchResult, result2 error) {
+	fake.searchPackagesMutex.Lock()
+	defer fake.searchPackagesMutex.Unlock()
+	fake.SearchPackagesStub = nil
+	fake.searchPackagesReturns = struct {
+		result1 []resource.SearchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) SearchPackagesReturnsOnCall(i int, result1 []resource.SearchResult, result2 error) {
+	fake.searchPackagesMutex.Lock()
+	defer fake.searchPackagesMutex.Unlock()
+	fake.SearchPackagesStub = nil
+	if fake.searchPackagesReturnsOnCall == nil {
+		fake.searchPackagesReturnsOnCall = make(map[int]struct {
+			result1 []resource.SearchResult
+			result2 error
+		})
+	}
+	fake.searchPackagesReturnsOnCall[i] = struct {
+		result1 []resource.SearchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) SecurityReport(arg1 resource.Package, arg2 string, arg3 string) ([]byte, error) {
+	fake.securityReportMutex.Lock()
+	ret, specificReturn := fake.securityReportReturnsOnCall[len(fake.securityReportArgsForCall)]
+	fake.securityReportArgsForCall = append(fake.securityReportArgsForCall, struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SecurityReportStub
+	fakeReturns := fake.securityReportReturns
+	fake.recordInvocation("SecurityReport", []interface{}{arg1, arg2, arg3})
+	fake.securityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeArtifactHub) SecurityReportCallCount() int {
+	fake.securityReportMutex.RLock()
+	defer fake.securityReportMutex.RUnlock()
+	return len(fake.securityReportArgsForCall)
+}
+
+func (fake *FakeArtifactHub) SecurityReportCalls(stub func(resource.Package, string, string) ([]byte, error)) {
+	fake.securityReportMutex.Lock()
+	defer fake.securityReportMutex.Unlock()
+	fake.SecurityReportStub = stub
+}
+
+func (fake *FakeArtifactHub) SecurityReportArgsForCall(i int) (resource.Package, string, string) {
+	fake.securityReportMutex.RLock()
+	defer fake.securityReportMutex.RUnlock()
+	argsForCall := fake.securityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeArtifactHub) SecurityReportReturns(result1 []byte, result2 error) {
+	fake.securityReportMutex.Lock()
+	defer fake.securityReportMutex.Unlock()
+	fake.SecurityReportStub = nil
+	fake.securityReportReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) SecurityReportReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.securityReportMutex.Lock()
+	defer fake.securityReportMutex.Unlock()
+	fake.SecurityReportStub = nil
+	if fake.securityReportReturnsOnCall == nil {
+		fake.securityReportReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.securityReportReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) Values(arg1 resource.Package, arg2 string, arg3 string) ([]byte, error) {
+	fake.valuesMutex.Lock()
+	ret, specificReturn := fake.valuesReturnsOnCall[len(fake.valuesArgsForCall)]
+	fake.valuesArgsForCall = append(fake.valuesArgsForCall, struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ValuesStub
+	fakeReturns := fake.valuesReturns
+	fake.recordInvocation("Values", []interface{}{arg1, arg2, arg3})
+	fake.valuesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeArtifactHub) ValuesCallCount() int {
+	fake.valuesMutex.RLock()
+	defer fake.valuesMutex.RUnlock()
+	return len(fake.valuesArgsForCall)
+}
+
+func (fake *FakeArtifactHub) ValuesCalls(stub func(resource.Package, string, string) ([]byte, error)) {
+	fake.valuesMutex.Lock()
+	defer fake.valuesMutex.Unlock()
+	fake.ValuesStub = stub
+}
+
+func (fake *FakeArtifactHub) ValuesArgsForCall(i int) (resource.Package, string, string) {
+	fake.valuesMutex.RLock()
+	defer fake.valuesMutex.RUnlock()
+	argsForCall := fake.valuesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeArtifactHub) ValuesReturns(result1 []byte, result2 error) {
+	fake.valuesMutex.Lock()
+	defer fake.valuesMutex.Unlock()
+	fake.ValuesStub = nil
+	fake.valuesReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) ValuesReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.valuesMutex.Lock()
+	defer fake.valuesMutex.Unlock()
+	fake.ValuesStub = nil
+	if fake.valuesReturnsOnCall == nil {
+		fake.valuesReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.valuesReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) ValuesSchema(arg1 resource.Package, arg2 string, arg3 string) ([]byte, error) {
+	fake.valuesSchemaMutex.Lock()
+	ret, specificReturn := fake.valuesSchemaReturnsOnCall[len(fake.valuesSchemaArgsForCall)]
+	fake.valuesSchemaArgsForCall = append(fake.valuesSchemaArgsForCall, struct {
+		arg1 resource.Package
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ValuesSchemaStub
+	fakeReturns := fake.valuesSchemaReturns
+	fake.recordInvocation("ValuesSchema", []interface{}{arg1, arg2, arg3})
+	fake.valuesSchemaMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeArtifactHub) ValuesSchemaCallCount() int {
+	fake.valuesSchemaMutex.RLock()
+	defer fake.valuesSchemaMutex.RUnlock()
+	return len(fake.valuesSchemaArgsForCall)
+}
+
+func (fake *FakeArtifactHub) ValuesSchemaCalls(stub func(resource.Package, string, string) ([]byte, error)) {
+	fake.valuesSchemaMutex.Lock()
+	defer fake.valuesSchemaMutex.Unlock()
+	fake.ValuesSchemaStub = stub
+}
+
+func (fake *FakeArtifactHub) ValuesSchemaArgsForCall(i int) (resource.Package, string, string) {
+	fake.valuesSchemaMutex.RLock()
+	defer fake.valuesSchemaMutex.RUnlock()
+	argsForCall := fake.valuesSchemaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeArtifactHub) ValuesSchemaReturns(result1 []byte, result2 error) {
+	fake.valuesSchemaMutex.Lock()
+	defer fake.valuesSchemaMutex.Unlock()
+	fake.ValuesSchemaStub = nil
+	fake.valuesSchemaReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeArtifactHub) ValuesSchemaReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.valuesSchemaMutex.Lock()
+	defer fake.valuesSchemaMutex.Unlock()
+	fake.ValuesSchemaStub = nil
+	if fake.valuesSchemaReturnsOnCall == nil {
+		fake.valuesSchemaReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.valuesSchemaReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeArtifactHub) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.listHelmVersionMutex.RLock()
-	defer fake.listHelmVersionMutex.RUnlock()
-	fake.listHelmVersionsMutex.RLock()
-	defer fake.listHelmVersionsMutex.RUnlock()
+	fake.changelogMutex.RLock()
+	defer fake.changelogMutex.RUnlock()
+	fake.downloadMutex.RLock()
+	defer fake.downloadMutex.RUnlock()
+	fake.listVersionMutex.RLock()
+	defer fake.listVersionMutex.RUnlock()
+	fake.listVersionsMutex.RLock()
+	defer fake.listVersionsMutex.RUnlock()
+	fake.searchPackagesMutex.RLock()
+	defer fake.searchPackagesMutex.RUnlock()
+	fake.securityReportMutex.RLock()
+	defer fake.securityReportMutex.RUnlock()
+	fake.valuesMutex.RLock()
+	defer fake.valuesMutex.RUnlock()
+	fake.valuesSchemaMutex.RLock()
+	defer fake.valuesSchemaMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
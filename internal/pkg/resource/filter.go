@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// PreReleasesInclude emits pre-release and stable versions
+	PreReleasesInclude = "include"
+	// PreReleasesExclude emits only stable versions
+	PreReleasesExclude = "exclude"
+	// PreReleasesOnly emits only pre-release versions
+	PreReleasesOnly = "only"
+)
+
+// filter removes all versions that do not match the configuration of the Source
+func (s Source) filter(versions []Version) ([]Version, error) {
+	constraint, err := s.constraint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.preReleasePolicy()
+
+	if err != nil {
+		return nil, err
+	}
+
+	minAge, err := s.minAge()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var publishedBefore = time.Now().Add(-minAge)
+
+	filtered := []Version{}
+
+	for _, version := range versions {
+		if version.Deprecated && !s.IncludeDeprecated {
+			continue
+		}
+
+		if minAge > 0 && version.CreatedAt.After(publishedBefore) {
+			continue
+		}
+
+		v, err := semver.NewVersion(version.Version)
+
+		if err != nil {
+			if constraint != nil || policy == PreReleasesOnly {
+				continue
+			}
+			filtered = append(filtered, version)
+			continue
+		}
+
+		if !matchesPreReleasePolicy(policy, v) {
+			continue
+		}
+
+		if constraint != nil && !matchesConstraint(constraint, v) {
+			continue
+		}
+
+		filtered = append(filtered, version)
+	}
+
+	return filtered, nil
+}
+
+// constraint returns the parsed version constraint of the Source or nil if none is configured
+func (s Source) constraint() (*semver.Constraints, error) {
+	if len(s.VersionConstraint) == 0 {
+		return nil, nil
+	}
+
+	constraint, err := semver.NewConstraint(s.VersionConstraint)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %s: %s", s.VersionConstraint, err)
+	}
+
+	return constraint, nil
+}
+
+// minAge returns the minimum age of a version before it is emitted
+func (s Source) minAge() (time.Duration, error) {
+	if len(s.MinAge) == 0 {
+		return 0, nil
+	}
+
+	minAge, err := time.ParseDuration(s.MinAge)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid min_age %s: %s", s.MinAge, err)
+	}
+
+	return minAge, nil
+}
+
+// preReleasePolicy returns the pre-release policy of the Source which defaults to PreReleasesInclude
+func (s Source) preReleasePolicy() (string, error) {
+	switch s.PreReleases {
+	case "":
+		return PreReleasesInclude, nil
+	case PreReleasesInclude, PreReleasesExclude, PreReleasesOnly:
+		return s.PreReleases, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid pre_releases policy %s: expected one of %s, %s or %s",
+			s.PreReleases,
+			PreReleasesExclude,
+			PreReleasesInclude,
+			PreReleasesOnly,
+		)
+	}
+}
+
+func matchesPreReleasePolicy(policy string, v *semver.Version) bool {
+	switch policy {
+	case PreReleasesExclude:
+		return len(v.Prerelease()) == 0
+	case PreReleasesOnly:
+		return len(v.Prerelease()) > 0
+	default:
+		return true
+	}
+}
+
+// matchesConstraint checks the version against the constraint.
+// A constraint without a pre-release never matches pre-release versions,
+// therefore pre-releases are checked by their release version instead.
+func matchesConstraint(constraint *semver.Constraints, v *semver.Version) bool {
+	if len(v.Prerelease()) > 0 {
+		release, err := v.SetPrerelease("")
+
+		if err != nil {
+			return false
+		}
+
+		return constraint.Check(&release)
+	}
+
+	return constraint.Check(v)
+}
@@ -1,58 +1,316 @@
 package resource
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Get metadata for GetRequest will fetch meta information for the given helm chart version
+// Get metadata for GetRequest will fetch meta information for the given package version
 func Get(request GetRequest, path string, repository ArtifactHub) (*GetResponse, error) {
 
-	version, err := repository.ListHelmVersion(Package{
-		RepositoryName: request.Source.RepositoryName,
-		PackageName:    request.Source.PackageName,
-		ApiKey:         request.Source.ApiKey,
-	}, request.Version.Version)
+	if err := request.Source.validateApiKey(); err != nil {
+		return nil, err
+	}
+
+	if _, err := request.Source.kind(); err != nil {
+		return nil, err
+	}
+
+	if len(request.Source.Packages) > 0 {
+		if err := request.Source.validatePackages(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(request.Source.Search) > 0 {
+		if err := request.Source.validateSearch(); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Source.RequireSignature && len(request.Source.Keyring) == 0 {
+		return nil, fmt.Errorf("require_signature needs a keyring")
+	}
+
+	if len(request.Params.SinceVersion) > 0 && !request.Params.Changelog {
+		return nil, fmt.Errorf("since_version needs changelog")
+	}
+
+	if len(request.Params.MaxSeverity) > 0 {
+		if _, err := severityRank(request.Params.MaxSeverity); err != nil {
+			return nil, err
+		}
+	}
+
+	selectedMetadata, err := request.Params.selectedMetadata()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var metadata = &Metadata{}
-	metadata.append("app_version", version.AppVersion)
-	metadata.append("charts_url", version.Repository.Url)
-	metadata.append("chart_download_url", version.ContentUrl)
-	metadata.append("name", version.Name)
-	metadata.append("organization_name", version.Repository.OrganizationDisplayName)
-	metadata.append("repository_name", version.Repository.Name)
-	metadata.append("repository_display_name", version.Repository.DisplayName)
-	metadata.append("version", version.Version)
+	if len(request.Source.Search) > 0 && len(request.Version.Package) > 0 {
+		request.Source, err = request.Source.pin(request.Version.Package)
+	} else if len(request.Source.Search) > 0 {
+		request.Source, err = request.Source.resolve(repository)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Source.Packages) > 0 {
+		return getPackages(request, path, repository)
+	}
+
+	if request.Source.catalog() {
+		return getCatalog(request, path, repository)
+	}
+
+	pkg := request.Source.pkg()
+	version, err := repository.ListVersion(pkg, request.Version.Version)
+
+	var apiError *APIError
+
+	if errors.As(err, &apiError) && apiError.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("version %s is not available anymore, it was yanked or deleted upstream: %w", request.Version.Version, err)
+	}
+
+	if err != nil {
+		return nil, explain(err)
+	}
+
+	if len(request.Params.MaxSeverity) > 0 {
+		if err := checkSeverity(version, request.Params.MaxSeverity); err != nil {
+			return nil, err
+		}
+	}
+
+	var metadata = versionMetadata(version)
+	var files = &Metadata{}
+	files.append("digest", version.Digest)
+	files.append("deprecated", strconv.FormatBool(version.Deprecated))
+
+	dependencies, err := dependenciesJSON(version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files.append("dependencies.json", dependencies)
+
+	if len(version.Readme) > 0 {
+		files.append("README.md", version.Readme)
+	}
+
+	if request.Params.Values && len(version.PackageID) == 0 {
+		return nil, fmt.Errorf("no package id available to get the values of %s version %s", version.Name, version.Version)
+	}
+
+	if request.Params.Values && pkg.Kind == KindHelm {
+		values, err := repository.Values(pkg, version.PackageID, version.Version)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get values: %w", explain(err))
+		}
+
+		files.append("values.yaml", string(values))
+	}
+
+	if request.Params.Values && version.HasValuesSchema {
+		schema, err := repository.ValuesSchema(pkg, version.PackageID, version.Version)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get values schema: %w", explain(err))
+		}
+
+		files.append("values.schema.json", string(schema))
+	}
+
+	if request.Params.Changelog && version.HasChangelog {
+		if len(version.PackageID) == 0 {
+			return nil, fmt.Errorf("no package id available to get the changelog of %s version %s", version.Name, version.Version)
+		}
+
+		entries, err := repository.Changelog(pkg, version.PackageID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get changelog: %w", explain(err))
+		}
+
+		changes, err := changelog(entries, version, request.Params.SinceVersion)
+
+		if err != nil {
+			return nil, err
+		}
+
+		files.append("CHANGELOG.md", changes)
+	}
+
+	if version.SecurityReportSummary != nil {
+		summary, err := securitySummary(version)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal security summary: %s", err)
+		}
+
+		files.append("security_summary.json", string(summary))
+	}
+
+	if version.SecurityReportSummary != nil && request.Params.SecurityReport {
+		report, err := repository.SecurityReport(pkg, version.PackageID, version.Version)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get security report: %w", explain(err))
+		}
+
+		files.append("security_report.json", string(report))
+	}
 
 	if err := os.MkdirAll(path, os.ModePerm); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %s", err)
 	}
-	for _, metadatum := range *metadata {
-		if err := ioutil.WriteFile(filepath.Join(path, metadatum.Name), []byte(metadatum.Value), 0600); err != nil {
-			return nil, fmt.Errorf("failed to write %s: %s", metadatum.Name, err)
+
+	var verifySignature = len(request.Source.Keyring) > 0
+
+	if request.Params.DownloadChart || verifySignature {
+		digest, err := downloadChart(version, path, repository)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !request.Params.DownloadChart {
+			defer func() { _ = os.Remove(downloadedChart(version, path)) }()
 		}
+
+		if verifySignature {
+			err := verifyProvenance(version, digest, request.Source.Keyring, repository)
+
+			if err != nil && request.Source.RequireSignature {
+				return nil, err
+			}
+
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "signature verification failed: %s\n", err)
+			}
+
+			files.append("signature_verified", strconv.FormatBool(err == nil))
+		}
+	}
+
+	if err := writeFiles(path, append(*metadata, *files...)); err != nil {
+		return nil, err
 	}
 
+	metadata.appendExtraMetadata(version, selectedMetadata)
+
 	return &GetResponse{
 		Version: Version{
-			CreatedAt: time.Time(version.TS).UTC(),
-			Version:   version.Version,
+			CreatedAt:    time.Time(version.TS).UTC(),
+			Version:      version.Version,
+			Dependencies: request.Version.Dependencies,
+			Package:      request.Version.Package,
 		},
 		Metadata: *metadata,
 	}, nil
 }
 
+// downloadChart downloads the chart tarball of the version to <name>-<version>.tgz
+// and verifies it against the digest of the version. A missing digest is reported on stderr.
+// The SHA-256 of the tarball is returned.
+func downloadChart(version *PackageVersion, path string, repository ArtifactHub) (string, error) {
+	if len(version.ContentUrl) == 0 {
+		return "", fmt.Errorf("no download url available for %s version %s", version.Name, version.Version)
+	}
+
+	filename := downloadedChart(version, path)
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %s", filename, err)
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if err := repository.Download(version.ContentUrl, io.MultiWriter(file, hash)); err != nil {
+		return "", fmt.Errorf("failed to download chart: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %s", filename, err)
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	expected := strings.ToLower(strings.TrimPrefix(version.Digest, "sha256:"))
+
+	if len(expected) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s could not be verified because artifacthub.io provides no digest\n", filepath.Base(filename))
+	}
+
+	if len(expected) > 0 && digest != expected {
+		_ = os.Remove(filename)
+		return "", fmt.Errorf("digest mismatch for %s: expected %s but was %s", filepath.Base(filename), expected, digest)
+	}
+
+	return digest, nil
+}
+
+// downloadedChart returns the path of the downloaded chart tarball of the version
+func downloadedChart(version *PackageVersion, path string) string {
+	return filepath.Clean(filepath.Join(path, fmt.Sprintf("%s-%s.tgz", version.Name, version.Version)))
+}
+
+func writeFiles(path string, files Metadata) error {
+	for _, file := range files {
+		if err := ioutil.WriteFile(filepath.Join(path, file.Name), []byte(file.Value), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %s", file.Name, err)
+		}
+	}
+	return nil
+}
+
+func versionMetadata(version *PackageVersion) *Metadata {
+	var metadata = &Metadata{}
+	metadata.append("app_version", version.AppVersion)
+	metadata.append("charts_url", version.Repository.Url)
+	metadata.append("chart_download_url", version.ContentUrl)
+	metadata.append("name", version.Name)
+	metadata.append("organization_name", version.Repository.OrganizationDisplayName)
+	metadata.append("repository_name", version.Repository.Name)
+	metadata.append("repository_display_name", version.Repository.DisplayName)
+	metadata.append("version", version.Version)
+	return metadata
+}
+
 // GetRequest contains the information for a specific Source and Version
 type GetRequest struct {
-	Source  Source  `json:"source"`
-	Version Version `json:"version"`
+	Source  Source    `json:"source"`
+	Version Version   `json:"version"`
+	Params  GetParams `json:"params"`
+}
+
+// GetParams contains the optional behavior of a GetRequest
+type GetParams struct {
+	DownloadChart   bool     `json:"download_chart"`
+	MaxSeverity     string   `json:"max_severity"`
+	SecurityReport  bool     `json:"security_report"`
+	Changelog       bool     `json:"changelog"`
+	SinceVersion    string   `json:"since_version"`
+	Values          bool     `json:"values"`
+	MetadataInclude []string `json:"metadata_include"`
+	MetadataExclude []string `json:"metadata_exclude"`
 }
 
 // GetResponse contains a Version and Metadata for a Version
@@ -1,21 +1,34 @@
 package resource_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
 	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
 var _ = Describe("Artifacthub Resource In", func() {
 
 	var (
-		artifacthub     *fakes.FakeArtifactHub
-		getRequest      resource.GetRequest
-		fixedTime       time.Time
-		testHelmVersion *resource.HelmVersion
+		artifacthub        *fakes.FakeArtifactHub
+		getRequest         resource.GetRequest
+		fixedTime          time.Time
+		testPackageVersion *resource.PackageVersion
 	)
 
 	BeforeEach(func() {
@@ -32,7 +45,7 @@ var _ = Describe("Artifacthub Resource In", func() {
 				CreatedAt: fixedTime,
 			},
 		}
-		testHelmVersion = &resource.HelmVersion{
+		testPackageVersion = &resource.PackageVersion{
 			AppVersion:        "8.2.1",
 			ContentUrl:        "https://git.local/",
 			TS:                resource.Epoch(fixedTime),
@@ -49,27 +62,28 @@ var _ = Describe("Artifacthub Resource In", func() {
 	})
 
 	When("in is called with valid arguments", func() {
-		It("should call list helm version with specific version", func() {
+		It("should call list version with specific version", func() {
 
-			artifacthub.ListHelmVersionReturns(testHelmVersion, nil)
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
 
 			_, err := resource.Get(getRequest, os.TempDir(), artifacthub)
 			Expect(err).ToNot(HaveOccurred())
 
-			pkg, version := artifacthub.ListHelmVersionArgsForCall(0)
+			pkg, version := artifacthub.ListVersionArgsForCall(0)
 			Expect(pkg).To(Equal(resource.Package{
+				Kind:           "helm",
 				RepositoryName: "acme-charts",
 				PackageName:    "my-package-name",
 				ApiKey:         "some-fake-api-key",
 			}))
-			Expect(artifacthub.ListHelmVersionCallCount()).To(Equal(1))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(1))
 			Expect(version).To(Equal("9.2.4"))
 
 		})
 
 		It("should return a response with expected version and metadata", func() {
 
-			artifacthub.ListHelmVersionReturns(testHelmVersion, nil)
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
 
 			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
 
@@ -112,9 +126,869 @@ var _ = Describe("Artifacthub Resource In", func() {
 					Name:  "version",
 					Value: "9.2.4",
 				},
+				{
+					Name:  "deprecated",
+					Value: "false",
+				},
+				{
+					Name:  "signed",
+					Value: "false",
+				},
 			}))
 
 		})
 	})
 
+	When("in is called for a version with extra metadata", func() {
+
+		BeforeEach(func() {
+			testPackageVersion.Description = "Some package"
+			testPackageVersion.HomeUrl = "https://some-package.local/"
+			testPackageVersion.License = "Apache-2.0"
+			testPackageVersion.Maintainers = []resource.Maintainer{{Name: "acme", Email: "acme@example.local"}, {Name: "bob"}}
+			testPackageVersion.Links = []resource.Link{{Name: "source", Url: "https://git.local/some-package"}}
+			testPackageVersion.Data.KubeVersion = ">=1.19.0-0"
+			testPackageVersion.Signed = true
+			testPackageVersion.Digest = "some-digest"
+			testPackageVersion.SecurityReportSummary = map[string]int{"high": 3, "medium": 5}
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+		})
+
+		It("should return all extra metadata by default", func() {
+			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Metadata).To(ContainElements(resource.Metadata{
+				{Name: "description", Value: "Some package"},
+				{Name: "home_url", Value: "https://some-package.local/"},
+				{Name: "license", Value: "Apache-2.0"},
+				{Name: "maintainers", Value: "acme <acme@example.local>, bob"},
+				{Name: "links", Value: "source: https://git.local/some-package"},
+				{Name: "kubernetes_version", Value: ">=1.19.0-0"},
+				{Name: "deprecated", Value: "false"},
+				{Name: "signed", Value: "true"},
+				{Name: "digest", Value: "some-digest"},
+				{Name: "security_summary", Value: "critical: 0, high: 3, medium: 5, low: 0, unknown: 0"},
+			}))
+		})
+
+		It("should only return the included extra metadata", func() {
+			getRequest.Params.MetadataInclude = []string{"license", "signed"}
+
+			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Metadata).To(HaveLen(10))
+			Expect(response.Metadata).To(ContainElements(resource.Metadata{
+				{Name: "license", Value: "Apache-2.0"},
+				{Name: "signed", Value: "true"},
+			}))
+		})
+
+		It("should not return the excluded extra metadata", func() {
+			getRequest.Params.MetadataExclude = []string{"description", "maintainers", "security_summary"}
+
+			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Metadata).To(HaveLen(15))
+			Expect(metadataNames(response.Metadata)).ToNot(ContainElements("description", "maintainers", "security_summary"))
+		})
+
+		It("should return an error for unknown metadata", func() {
+			getRequest.Params.MetadataExclude = []string{"unknown"}
+
+			_, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("unknown metadata unknown")))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+	})
+
+	When("in is called for a version with readme, values and values schema", func() {
+
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			testPackageVersion.PackageID = "some-package-id"
+			testPackageVersion.Readme = "# README"
+			testPackageVersion.HasValuesSchema = true
+			getRequest.Params.Values = true
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+			artifacthub.ValuesReturns([]byte("replicaCount: 1\n"), nil)
+			artifacthub.ValuesSchemaReturns([]byte(`{"type": "object"}`), nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should write the readme, values and values schema", func() {
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, packageID, version := artifacthub.ValuesArgsForCall(0)
+			Expect(packageID).To(Equal("some-package-id"))
+			Expect(version).To(Equal("9.2.4"))
+			testFileContent(outputDir, "README.md", "# README")
+			testFileContent(outputDir, "values.yaml", "replicaCount: 1\n")
+			testFileContent(outputDir, "values.schema.json", `{"type": "object"}`)
+		})
+
+		It("should not request the values schema if the version has none", func() {
+			testPackageVersion.HasValuesSchema = false
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ValuesSchemaCallCount()).To(Equal(0))
+			Expect(filepath.Join(outputDir, "values.schema.json")).ToNot(BeAnExistingFile())
+		})
+
+		It("should not request values for other kinds than helm", func() {
+			getRequest.Source.Kind = "falco"
+			testPackageVersion.HasValuesSchema = false
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ValuesCallCount()).To(Equal(0))
+		})
+
+		It("should return an error when the values could not be fetched", func() {
+			artifacthub.ValuesReturns(nil, fmt.Errorf("some error"))
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
+
+		It("should only write the readme by default", func() {
+			getRequest.Params.Values = false
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ValuesCallCount()).To(Equal(0))
+			Expect(artifacthub.ValuesSchemaCallCount()).To(Equal(0))
+			testFileContent(outputDir, "README.md", "# README")
+			Expect(filepath.Join(outputDir, "values.yaml")).ToNot(BeAnExistingFile())
+		})
+
+		It("should return an error when the version has no package id", func() {
+			testPackageVersion.PackageID = ""
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("no package id available")))
+			Expect(response).To(BeNil())
+			Expect(artifacthub.ValuesCallCount()).To(Equal(0))
+		})
+	})
+
+	When("in is called for a version with a changelog", func() {
+
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			testPackageVersion.PackageID = "some-package-id"
+			testPackageVersion.HasChangelog = true
+			getRequest.Params.Changelog = true
+			testPackageVersion.AvailableVersions = []resource.AvailableVersion{
+				{Version: "9.1.0"}, {Version: "9.2.0"}, {Version: "9.2.4"}, {Version: "9.3.0"},
+			}
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			var entries []resource.ChangelogEntry
+			Expect(json.Unmarshal([]byte(`[
+				{"version": "9.3.0", "changes": [{"kind": "added", "description": "not yet fetched"}]},
+				{"version": "9.2.4", "changes": [{"kind": "fixed", "description": "some fix"}, "plain change"]},
+				{"version": "9.2.1", "changes": [{"kind": "security", "description": "some patch"}]},
+				{"version": "9.2.0", "changes": [{"kind": "added", "description": "some feature"}]},
+				{"version": "9.1.0", "changes": [{"kind": "added", "description": "old feature"}]}
+			]`), &entries)).To(Succeed())
+			artifacthub.ChangelogReturns(entries, nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should write the changes since the previous version", func() {
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, packageID := artifacthub.ChangelogArgsForCall(0)
+			Expect(packageID).To(Equal("some-package-id"))
+			testFileContent(outputDir, "CHANGELOG.md", "# Changelog\n\n## 9.2.4\n\n- Fixed: some fix\n- plain change\n\n## 9.2.1\n\n- Security: some patch\n")
+		})
+
+		It("should write the changes since the given version", func() {
+			getRequest.Params.SinceVersion = "9.1.0"
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "CHANGELOG.md"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("## 9.2.0"))
+			Expect(string(content)).ToNot(ContainSubstring("## 9.1.0"))
+			Expect(string(content)).ToNot(ContainSubstring("## 9.3.0"))
+		})
+
+		It("should return an error when the since version is invalid", func() {
+			getRequest.Params.SinceVersion = "yesterday"
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
+
+		It("should not request the changelog by default", func() {
+			getRequest.Params.Changelog = false
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ChangelogCallCount()).To(Equal(0))
+			Expect(filepath.Join(outputDir, "CHANGELOG.md")).ToNot(BeAnExistingFile())
+		})
+
+		It("should return an error when a since version is given without changelog", func() {
+			getRequest.Params.Changelog = false
+			getRequest.Params.SinceVersion = "9.1.0"
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError("since_version needs changelog"))
+			Expect(response).To(BeNil())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+
+		It("should return an error when the changelog could not be fetched", func() {
+			artifacthub.ChangelogReturns(nil, fmt.Errorf("some error"))
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("failed to get changelog")))
+			Expect(response).To(BeNil())
+		})
+	})
+
+	When("in is called for a version with dependencies", func() {
+
+		It("should write the dependencies and keep the dependencies digest of the version", func() {
+			outputDir, err := ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(outputDir)
+
+			getRequest.Version.Dependencies = "some-digest"
+			testPackageVersion.Data.Dependencies = []resource.Dependency{
+				{Name: "postgresql", Version: "10.2.0", Repository: "https://charts.bitnami.com/bitnami"},
+			}
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Version.Dependencies).To(Equal("some-digest"))
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "dependencies.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(MatchJSON(`[{"name": "postgresql", "version": "10.2.0", "repository": "https://charts.bitnami.com/bitnami"}]`))
+		})
+	})
+
+	When("in is called for a deprecated version", func() {
+
+		It("should write the deprecated file", func() {
+			outputDir, err := ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(outputDir)
+
+			testPackageVersion.Deprecated = true
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			_, err = resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			testFileContent(outputDir, "deprecated", "true")
+		})
+	})
+
+	When("in is called for a version which is not available anymore", func() {
+
+		It("should return an error that the version was yanked or deleted", func() {
+			artifacthub.ListVersionReturns(nil, &resource.APIError{StatusCode: http.StatusNotFound})
+
+			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("version 9.2.4 is not available anymore")))
+			Expect(response).To(BeNil())
+
+			var apiError *resource.APIError
+			Expect(errors.As(err, &apiError)).To(BeTrue())
+		})
+	})
+
+	When("in is called with packages", func() {
+
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			getRequest.Source.RepositoryName = ""
+			getRequest.Source.PackageName = ""
+			getRequest.Source.Packages = []resource.PackageSource{
+				{RepositoryName: "acme-charts", PackageName: "my-package-name"},
+				{RepositoryName: "other-charts", PackageName: "other-package"},
+			}
+			getRequest.Version = resource.Version{
+				Version:  "some-digest",
+				Manifest: "acme-charts/my-package-name@9.2.4,other-charts/other-package@1.0.0",
+				Changes:  "other-charts/other-package@1.0.0",
+			}
+			artifacthub.ListVersionStub = func(p resource.Package, version string) (*resource.PackageVersion, error) {
+				return &resource.PackageVersion{Name: p.PackageName, Version: version}, nil
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should get each package of the manifest into its own directory", func() {
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Version).To(Equal(getRequest.Version))
+			Expect(response.Metadata).To(ConsistOf(resource.Metadata{
+				{Name: "acme-charts/my-package-name", Value: "9.2.4"},
+				{Name: "other-charts/other-package", Value: "1.0.0"},
+			}))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(2))
+			testFileContent(filepath.Join(outputDir, "acme-charts", "my-package-name"), "version", "9.2.4")
+			testFileContent(filepath.Join(outputDir, "other-charts", "other-package"), "version", "1.0.0")
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "packages.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(MatchJSON(`[
+				{"repository_name": "acme-charts", "package_name": "my-package-name", "version": "9.2.4"},
+				{"repository_name": "other-charts", "package_name": "other-package", "version": "1.0.0"}
+			]`))
+
+			content, err = ioutil.ReadFile(filepath.Join(outputDir, "changes.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(MatchJSON(`[{"repository_name": "other-charts", "package_name": "other-package", "version": "1.0.0"}]`))
+		})
+
+		It("should return an error when a package is not part of the manifest", func() {
+			getRequest.Version.Manifest = "acme-charts/my-package-name@9.2.4"
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("package other-charts/other-package is not part of the version manifest")))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+
+		It("should return an error when a package could not be fetched", func() {
+			artifacthub.ListVersionStub = func(p resource.Package, version string) (*resource.PackageVersion, error) {
+				return nil, fmt.Errorf("some error occurred")
+			}
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("some error occurred")))
+		})
+	})
+
+	When("in is called with a repository only", func() {
+
+		var (
+			outputDir string
+			results   []resource.SearchResult
+		)
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			getRequest.Source.PackageName = ""
+			results = []resource.SearchResult{
+				{Name: "my-package-name", Version: "9.2.4", Repository: resource.Repository{Name: "acme-charts"}},
+				{Name: "other-package", Version: "1.1.0", Repository: resource.Repository{Name: "acme-charts"}},
+			}
+			artifacthub.SearchPackagesStub = func(p resource.Package, query resource.SearchQuery) ([]resource.SearchResult, error) {
+				return results, nil
+			}
+
+			check, err := resource.Check(resource.CheckRequest{Source: getRequest.Source}, artifacthub)
+			Expect(err).ToNot(HaveOccurred())
+			getRequest.Version = (*check)[0]
+			getRequest.Version.Previous = "some-digest"
+			getRequest.Version.Changes = "acme-charts/other-package@1.1.0"
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should write the changed packages of the version", func() {
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Version).To(Equal(getRequest.Version))
+			Expect(response.Metadata).To(ConsistOf(resource.Metadata{
+				{Name: "acme-charts/other-package", Value: "1.1.0"},
+			}))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "changes.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(MatchJSON(`[{"repository_name": "acme-charts", "package_name": "other-package", "version": "1.1.0"}]`))
+		})
+
+		It("should search the packages again and write their versions", func() {
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(2))
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "packages.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(MatchJSON(`[
+				{"repository_name": "acme-charts", "package_name": "my-package-name", "version": "9.2.4"},
+				{"repository_name": "acme-charts", "package_name": "other-package", "version": "1.1.0"}
+			]`))
+		})
+
+		It("should write the current versions when the packages changed since the check", func() {
+			results[1].Version = "1.2.0"
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Version).To(Equal(getRequest.Version))
+			testFileContent(outputDir, "changes.json", "[\n  {\n    \"repository_name\": \"acme-charts\",\n    \"package_name\": \"other-package\",\n    \"version\": \"1.1.0\"\n  }\n]")
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "packages.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`"version": "1.2.0"`))
+		})
+
+		It("should write no changed packages for the first version", func() {
+			getRequest.Version.Previous = ""
+			getRequest.Version.Changes = ""
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			testFileContent(outputDir, "changes.json", "[]")
+		})
+
+		It("should return an error for invalid changes", func() {
+			getRequest.Version.Changes = "other-package"
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("invalid manifest entry other-package")))
+		})
+	})
+
+	When("in is called with a search", func() {
+
+		It("should get the version of the package found", func() {
+			getRequest.Source.RepositoryName = ""
+			getRequest.Source.PackageName = ""
+			getRequest.Source.Search = "my-package-name"
+			artifacthub.SearchPackagesReturns([]resource.SearchResult{
+				{Name: "my-package-name", Repository: resource.Repository{Name: "acme-charts"}},
+			}, nil)
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			_, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			pkg, version := artifacthub.ListVersionArgsForCall(0)
+			Expect(pkg).To(Equal(resource.Package{
+				Kind:           "helm",
+				RepositoryName: "acme-charts",
+				PackageName:    "my-package-name",
+				ApiKey:         "some-fake-api-key",
+			}))
+			Expect(version).To(Equal("9.2.4"))
+		})
+
+		It("should get the version of the package pinned in the version without searching", func() {
+			getRequest.Source.RepositoryName = ""
+			getRequest.Source.PackageName = ""
+			getRequest.Source.Search = "my-package-name"
+			getRequest.Version.Package = "olm/acme-operators/my-package-name-operator"
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			response, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.SearchPackagesCallCount()).To(Equal(0))
+			pkg, _ := artifacthub.ListVersionArgsForCall(0)
+			Expect(pkg).To(Equal(resource.Package{
+				Kind:           "olm",
+				RepositoryName: "acme-operators",
+				PackageName:    "my-package-name-operator",
+				ApiKey:         "some-fake-api-key",
+			}))
+			Expect(response.Version.Package).To(Equal("olm/acme-operators/my-package-name-operator"))
+		})
+
+		It("should return an error for an invalid pinned package", func() {
+			getRequest.Source.RepositoryName = ""
+			getRequest.Source.PackageName = ""
+			getRequest.Source.Search = "my-package-name"
+			getRequest.Version.Package = "my-package-name"
+
+			_, err := resource.Get(getRequest, os.TempDir(), artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("invalid package my-package-name of the version")))
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+	})
+
+	When("in is called with download_chart", func() {
+
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			getRequest.Params.DownloadChart = true
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should download the chart into the output directory", func() {
+			artifacthub.DownloadStub = func(url string, w io.Writer) error {
+				_, err := w.Write([]byte("chart"))
+				return err
+			}
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.DownloadCallCount()).To(Equal(1))
+			url, _ := artifacthub.DownloadArgsForCall(0)
+			Expect(url).To(Equal("https://git.local/"))
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "some-package-9.2.4.tgz"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal("chart"))
+		})
+
+		It("should verify the chart against the digest", func() {
+			testPackageVersion.Digest = fmt.Sprintf("%x", sha256.Sum256([]byte("chart")))
+			artifacthub.DownloadStub = func(url string, w io.Writer) error {
+				_, err := w.Write([]byte("chart"))
+				return err
+			}
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Join(outputDir, "some-package-9.2.4.tgz")).To(BeAnExistingFile())
+
+			content, err := ioutil.ReadFile(filepath.Join(outputDir, "digest"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal(testPackageVersion.Digest))
+		})
+
+		It("should return an error when the digest does not match", func() {
+			testPackageVersion.Digest = fmt.Sprintf("%x", sha256.Sum256([]byte("other chart")))
+			artifacthub.DownloadStub = func(url string, w io.Writer) error {
+				_, err := w.Write([]byte("chart"))
+				return err
+			}
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("digest mismatch")))
+			Expect(response).To(BeNil())
+			Expect(filepath.Join(outputDir, "some-package-9.2.4.tgz")).ToNot(BeAnExistingFile())
+		})
+
+		It("should return an error when the download fails", func() {
+			artifacthub.DownloadReturns(fmt.Errorf("connection refused"))
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
+
+		It("should keep the api error of a failed download", func() {
+			artifacthub.DownloadReturns(&resource.APIError{StatusCode: http.StatusNotFound})
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			var apiError *resource.APIError
+			Expect(errors.As(err, &apiError)).To(BeTrue())
+			Expect(apiError.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should not download the chart by default", func() {
+			getRequest.Params.DownloadChart = false
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.DownloadCallCount()).To(Equal(0))
+		})
+	})
+
+	When("in is called for a version with a security report", func() {
+
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			testPackageVersion.PackageID = "some-package-id"
+			testPackageVersion.SecurityReportSummary = map[string]int{"critical": 1, "high": 2}
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+			artifacthub.SecurityReportReturns([]byte(`{"some-image": {}}`), nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("should write the security summary and report", func() {
+			getRequest.Params.SecurityReport = true
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, packageID, version := artifacthub.SecurityReportArgsForCall(0)
+			Expect(packageID).To(Equal("some-package-id"))
+			Expect(version).To(Equal("9.2.4"))
+
+			summary, err := ioutil.ReadFile(filepath.Join(outputDir, "security_summary.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(summary).To(MatchJSON(`{"critical": 1, "high": 2, "medium": 0, "low": 0, "unknown": 0}`))
+			testFileContent(outputDir, "security_report.json", `{"some-image": {}}`)
+		})
+
+		It("should only write the security summary by default", func() {
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.SecurityReportCallCount()).To(Equal(0))
+			Expect(filepath.Join(outputDir, "security_summary.json")).To(BeAnExistingFile())
+			Expect(filepath.Join(outputDir, "security_report.json")).ToNot(BeAnExistingFile())
+		})
+
+		It("should return an error when the requested security report could not be fetched", func() {
+			getRequest.Params.SecurityReport = true
+			artifacthub.SecurityReportReturns(nil, fmt.Errorf("some error"))
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("failed to get security report")))
+			Expect(response).To(BeNil())
+		})
+
+		It("should fail when the max severity is exceeded", func() {
+			getRequest.Params.MaxSeverity = "high"
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("1 vulnerabilities with severity critical")))
+			Expect(response).To(BeNil())
+		})
+
+		It("should succeed when the max severity is not exceeded", func() {
+			getRequest.Params.MaxSeverity = "CRITICAL"
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should fail when a max severity is given but no security report is available", func() {
+			getRequest.Params.MaxSeverity = "critical"
+			testPackageVersion.SecurityReportSummary = nil
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
+
+		It("should return an error when the max severity is invalid", func() {
+			getRequest.Params.MaxSeverity = "severe"
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+	})
+
+	When("in is called with a keyring", func() {
+
+		var (
+			outputDir string
+			entity    *openpgp.Entity
+			chart     = []byte("chart")
+		)
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "resource-in-")
+			Expect(err).ToNot(HaveOccurred())
+
+			entity, err = openpgp.NewEntity("Acme", "", "acme@example.local", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			getRequest.Source.Keyring = armoredPublicKey(entity)
+			testPackageVersion.ContentUrl = "https://git.local/some-package-9.2.4.tgz"
+			testPackageVersion.Signed = true
+			testPackageVersion.Signatures = []string{"prov"}
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		downloads := func(prov []byte) func(url string, w io.Writer) error {
+			return func(url string, w io.Writer) error {
+				if strings.HasSuffix(url, ".prov") {
+					_, err := w.Write(prov)
+					return err
+				}
+				_, err := w.Write(chart)
+				return err
+			}
+		}
+
+		It("should verify the provenance of the chart", func() {
+			artifacthub.DownloadStub = downloads(provenance(entity, "some-package-9.2.4.tgz", chart))
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			url, _ := artifacthub.DownloadArgsForCall(1)
+			Expect(url).To(Equal("https://git.local/some-package-9.2.4.tgz.prov"))
+			testFileContent(outputDir, "signature_verified", "true")
+			Expect(filepath.Join(outputDir, "some-package-9.2.4.tgz")).ToNot(BeAnExistingFile())
+		})
+
+		It("should keep the verified chart with download_chart", func() {
+			getRequest.Params.DownloadChart = true
+			artifacthub.DownloadStub = downloads(provenance(entity, "some-package-9.2.4.tgz", chart))
+
+			_, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			testFileContent(outputDir, "some-package-9.2.4.tgz", "chart")
+		})
+
+		It("should record a signature of an unknown key as not verified", func() {
+			other, err := openpgp.NewEntity("Other", "", "other@example.local", nil)
+			Expect(err).ToNot(HaveOccurred())
+			artifacthub.DownloadStub = downloads(provenance(other, "some-package-9.2.4.tgz", chart))
+
+			_, err = resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			testFileContent(outputDir, "signature_verified", "false")
+		})
+
+		It("should fail when the checksum does not match and a signature is required", func() {
+			getRequest.Source.RequireSignature = true
+			artifacthub.DownloadStub = downloads(provenance(entity, "some-package-9.2.4.tgz", []byte("other chart")))
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("does not match the provenance file")))
+			Expect(response).To(BeNil())
+		})
+
+		It("should fail when the chart is unsigned and a signature is required", func() {
+			getRequest.Source.RequireSignature = true
+			testPackageVersion.Signed = false
+			artifacthub.DownloadStub = downloads(nil)
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("is not signed")))
+			Expect(response).To(BeNil())
+			Expect(artifacthub.DownloadCallCount()).To(Equal(1))
+		})
+
+		It("should fail when a signature is required without a keyring", func() {
+			getRequest.Source.RequireSignature = true
+			getRequest.Source.Keyring = ""
+
+			response, err := resource.Get(getRequest, outputDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
+	})
+
 })
+
+func testFileContent(dir string, filename string, expected string) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, filename))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(string(content)).To(Equal(expected))
+}
+
+func armoredPublicKey(entity *openpgp.Entity) string {
+	var buffer bytes.Buffer
+	w, err := armor.Encode(&buffer, openpgp.PublicKeyType, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(entity.Serialize(w)).To(Succeed())
+	Expect(w.Close()).To(Succeed())
+	return buffer.String()
+}
+
+func provenance(entity *openpgp.Entity, filename string, chart []byte) []byte {
+	var buffer bytes.Buffer
+	w, err := clearsign.Encode(&buffer, entity.PrivateKey, nil)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = fmt.Fprintf(w, "name: some-package\nversion: 9.2.4\n\n...\nfiles:\n  %s: sha256:%x\n", filename, sha256.Sum256(chart))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+func metadataNames(metadata resource.Metadata) []string {
+	var names []string
+	for _, pair := range metadata {
+		names = append(names, pair.Name)
+	}
+	return names
+}
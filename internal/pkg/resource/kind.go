@@ -0,0 +1,78 @@
+package resource
+
+import "fmt"
+
+// KindHelm is the default kind of a Package
+const KindHelm = "helm"
+
+// kinds contains the artifacthub package kinds by their name in the api paths and their id
+var kinds = []struct {
+	Name string
+	ID   int
+}{
+	{Name: KindHelm, ID: 0},
+	{Name: "falco", ID: 1},
+	{Name: "opa", ID: 2},
+	{Name: "olm", ID: 3},
+	{Name: "tbaction", ID: 4},
+	{Name: "krew", ID: 5},
+	{Name: "helm-plugin", ID: 6},
+	{Name: "tekton-task", ID: 7},
+	{Name: "keda-scaler", ID: 8},
+	{Name: "coredns", ID: 9},
+	{Name: "keptn", ID: 10},
+	{Name: "tekton-pipeline", ID: 11},
+	{Name: "container", ID: 12},
+	{Name: "kubewarden", ID: 13},
+	{Name: "gatekeeper", ID: 14},
+	{Name: "kyverno", ID: 15},
+	{Name: "knative-client-plugin", ID: 16},
+	{Name: "backstage", ID: 17},
+	{Name: "argo-template", ID: 18},
+	{Name: "kubearmor", ID: 19},
+	{Name: "kcl", ID: 20},
+	{Name: "headlamp", ID: 21},
+	{Name: "inspektor-gadget", ID: 22},
+	{Name: "tekton-stepaction", ID: 23},
+	{Name: "meshery", ID: 24},
+	{Name: "opencost", ID: 25},
+	{Name: "radius", ID: 26},
+	{Name: "bootc", ID: 27},
+}
+
+// kind returns the package kind of the Source which defaults to KindHelm
+func (s Source) kind() (string, error) {
+	if len(s.Kind) == 0 {
+		return KindHelm, nil
+	}
+
+	for _, kind := range kinds {
+		if kind.Name == s.Kind {
+			return kind.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown package kind %s", s.Kind)
+}
+
+// kindID returns the id of the package kind with the given name
+func kindID(name string) (int, bool) {
+	for _, kind := range kinds {
+		if kind.Name == name {
+			return kind.ID, true
+		}
+	}
+
+	return 0, false
+}
+
+// kindName returns the name of the package kind with the given id
+func kindName(id int) (string, bool) {
+	for _, kind := range kinds {
+		if kind.ID == id {
+			return kind.Name, true
+		}
+	}
+
+	return "", false
+}
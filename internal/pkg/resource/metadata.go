@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// extraMetadata contains the names of the optional metadata of a GetResponse in the order they are emitted
+var extraMetadata = []string{
+	"description",
+	"home_url",
+	"license",
+	"maintainers",
+	"links",
+	"kubernetes_version",
+	"deprecated",
+	"signed",
+	"digest",
+	"security_summary",
+}
+
+// selectedMetadata returns the names of the extra metadata selected by the include and exclude lists.
+// Without an include list all extra metadata are selected.
+func (p GetParams) selectedMetadata() ([]string, error) {
+	for _, name := range append(append([]string{}, p.MetadataInclude...), p.MetadataExclude...) {
+		if !contains(extraMetadata, name) {
+			return nil, fmt.Errorf("unknown metadata %s: expected one of %s", name, strings.Join(extraMetadata, ", "))
+		}
+	}
+
+	var selected []string
+
+	for _, name := range extraMetadata {
+		if len(p.MetadataInclude) > 0 && !contains(p.MetadataInclude, name) {
+			continue
+		}
+
+		if contains(p.MetadataExclude, name) {
+			continue
+		}
+
+		selected = append(selected, name)
+	}
+
+	return selected, nil
+}
+
+// appendExtraMetadata appends the selected extra metadata of the version, empty values are omitted
+func (m *Metadata) appendExtraMetadata(version *PackageVersion, selected []string) {
+	for _, name := range selected {
+		var value string
+
+		switch name {
+		case "description":
+			value = version.Description
+		case "home_url":
+			value = version.HomeUrl
+		case "license":
+			value = version.License
+		case "maintainers":
+			var maintainers []string
+			for _, maintainer := range version.Maintainers {
+				if len(maintainer.Email) > 0 {
+					maintainers = append(maintainers, fmt.Sprintf("%s <%s>", maintainer.Name, maintainer.Email))
+				} else {
+					maintainers = append(maintainers, maintainer.Name)
+				}
+			}
+			value = strings.Join(maintainers, ", ")
+		case "links":
+			var links []string
+			for _, link := range version.Links {
+				links = append(links, fmt.Sprintf("%s: %s", link.Name, link.Url))
+			}
+			value = strings.Join(links, ", ")
+		case "kubernetes_version":
+			value = version.Data.KubeVersion
+		case "deprecated":
+			value = strconv.FormatBool(version.Deprecated)
+		case "signed":
+			value = strconv.FormatBool(version.Signed)
+		case "digest":
+			value = version.Digest
+		case "security_summary":
+			if version.SecurityReportSummary != nil {
+				var counts []string
+				for i := len(severities) - 1; i >= 0; i-- {
+					counts = append(counts, fmt.Sprintf("%s: %d", severities[i], version.SecurityReportSummary[severities[i]]))
+				}
+				value = strings.Join(counts, ", ")
+			}
+		}
+
+		if len(value) > 0 {
+			m.append(name, value)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
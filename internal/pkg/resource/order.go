@@ -0,0 +1,189 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// OrderBySemver orders versions by their semantic version
+	OrderBySemver = "semver"
+	// OrderByPublishedAt orders versions by their publish time
+	OrderByPublishedAt = "published_at"
+)
+
+const (
+	// InvalidVersionsSkip drops versions which are not valid semver
+	InvalidVersionsSkip = "skip"
+	// InvalidVersionsPublishedAt orders versions which are not valid semver by their publish time
+	InvalidVersionsPublishedAt = "published_at"
+	// InvalidVersionsString orders versions which are not valid semver as strings after all valid versions
+	InvalidVersionsString = "string"
+)
+
+// order sorts the versions in ascending order according to the configuration of the Source
+func (s Source) order(versions []Version) ([]Version, error) {
+	orderBy, err := s.orderBy()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if orderBy == OrderByPublishedAt {
+		ordered := append([]Version{}, versions...)
+		sort.Slice(ordered, func(i, j int) bool {
+			return publishedBefore(ordered[i], ordered[j])
+		})
+		return ordered, nil
+	}
+
+	strategy, err := s.invalidVersionsStrategy()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var valid []Version
+	var parsed []*semver.Version
+	var invalid []Version
+
+	for _, version := range versions {
+		v, err := semver.NewVersion(version.Version)
+
+		if err == nil {
+			valid = append(valid, version)
+			parsed = append(parsed, v)
+			continue
+		}
+
+		if strategy == InvalidVersionsSkip {
+			printSkipped(s.PackageName, version, err)
+			continue
+		}
+
+		invalid = append(invalid, version)
+	}
+
+	sort.Sort(semverOrder{versions: valid, parsed: parsed})
+
+	if strategy == InvalidVersionsPublishedAt {
+		sort.Slice(invalid, func(i, j int) bool {
+			return publishedBefore(invalid[i], invalid[j])
+		})
+		return mergeByPublishedAt(valid, invalid), nil
+	}
+
+	sort.Slice(invalid, func(i, j int) bool {
+		return invalid[i].Version < invalid[j].Version
+	})
+
+	return append(valid, invalid...), nil
+}
+
+// mergeByPublishedAt merges the ordered invalid versions into the ordered semver versions.
+// An invalid version is placed before the first remaining semver version published after it,
+// so the result only depends on the order of both lists and not on the order of the api.
+func mergeByPublishedAt(valid []Version, invalid []Version) []Version {
+	merged := make([]Version, 0, len(valid)+len(invalid))
+
+	for len(valid) > 0 && len(invalid) > 0 {
+		if publishedBefore(invalid[0], valid[0]) {
+			merged = append(merged, invalid[0])
+			invalid = invalid[1:]
+		} else {
+			merged = append(merged, valid[0])
+			valid = valid[1:]
+		}
+	}
+
+	merged = append(merged, valid...)
+	return append(merged, invalid...)
+}
+
+// publishedBefore orders versions by their publish time and by their name if published at the same time
+func publishedBefore(a, b Version) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.Version < b.Version
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// orderBy returns the order of the versions which defaults to OrderBySemver
+func (s Source) orderBy() (string, error) {
+	switch s.OrderBy {
+	case "":
+		return OrderBySemver, nil
+	case OrderBySemver, OrderByPublishedAt:
+		return s.OrderBy, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid order_by %s: expected one of %s or %s",
+			s.OrderBy,
+			OrderBySemver,
+			OrderByPublishedAt,
+		)
+	}
+}
+
+// invalidVersionsStrategy returns the strategy for versions which are not valid semver.
+// The strategy defaults to InvalidVersionsSkip. InvalidVersionsString makes every invalid version
+// newer than all semver versions, e.g. a stray latest tag, so it needs an explicit OrderBySemver.
+func (s Source) invalidVersionsStrategy() (string, error) {
+	switch s.InvalidVersions {
+	case "":
+		return InvalidVersionsSkip, nil
+	case InvalidVersionsString:
+		if s.OrderBy != OrderBySemver {
+			return "", fmt.Errorf(
+				"invalid_versions %s orders invalid versions after all semver versions and needs order_by %s",
+				InvalidVersionsString,
+				OrderBySemver,
+			)
+		}
+		return s.InvalidVersions, nil
+	case InvalidVersionsSkip, InvalidVersionsPublishedAt:
+		return s.InvalidVersions, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid invalid_versions strategy %s: expected one of %s, %s or %s",
+			s.InvalidVersions,
+			InvalidVersionsSkip,
+			InvalidVersionsPublishedAt,
+			InvalidVersionsString,
+		)
+	}
+}
+
+// semverOrder sorts valid semver versions, equal versions like 1.0 and 1.0.0 are ordered by their name
+type semverOrder struct {
+	versions []Version
+	parsed   []*semver.Version
+}
+
+func (o semverOrder) Len() int { return len(o.versions) }
+
+func (o semverOrder) Swap(i, j int) {
+	o.versions[i], o.versions[j] = o.versions[j], o.versions[i]
+	o.parsed[i], o.parsed[j] = o.parsed[j], o.parsed[i]
+}
+
+func (o semverOrder) Less(i, j int) bool {
+	if c := o.parsed[i].Compare(o.parsed[j]); c != 0 {
+		return c < 0
+	}
+
+	return o.versions[i].Version < o.versions[j].Version
+}
+
+func printSkipped(name string, version Version, err error) {
+	_, _ = fmt.Fprintf(
+		os.Stderr,
+		"skipping version %s of package %s because it is not a valid semver version: %v\n",
+		version.Version,
+		name,
+		err,
+	)
+}
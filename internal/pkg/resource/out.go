@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPutTimeout  = time.Hour
+	defaultPutInterval = 30 * time.Second
+)
+
+// Put for PutRequest waits until the published version of the given package is available.
+//
+// Artifacthub processes repositories periodically and provides no api to request the processing
+// of a repository, see https://artifacthub.io/docs/topics/repositories/, so Put only polls for the version.
+func Put(request PutRequest, path string, repository ArtifactHub) (*PutResponse, error) {
+
+	err := request.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	versionName, err := request.Params.version(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseDuration(request.Params.Timeout, defaultPutTimeout)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %s", err)
+	}
+
+	interval, err := parseDuration(request.Params.Interval, defaultPutInterval)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval: %s", err)
+	}
+
+	pkg := request.Source.pkg()
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		version, err := repository.ListVersion(pkg, versionName)
+
+		if err == nil {
+			return &PutResponse{
+				Version: Version{
+					CreatedAt: time.Time(version.TS).UTC(),
+					Version:   version.Version,
+				},
+				Metadata: *versionMetadata(version),
+			}, nil
+		}
+
+		var apiError *APIError
+		if errors.As(err, &apiError) && apiError.StatusCode != http.StatusNotFound {
+			return nil, explain(err)
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return nil, fmt.Errorf("version %s did not become available within %s: %s", versionName, timeout, err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func (p PutRequest) validate() error {
+	if len(p.Source.PackageName) == 0 || len(p.Source.RepositoryName) == 0 {
+		return fmt.Errorf(
+			"package name: %s or repository name: %s should not be empty",
+			p.Source.PackageName,
+			p.Source.RepositoryName,
+		)
+	}
+
+	if err := p.Source.validateApiKey(); err != nil {
+		return err
+	}
+
+	if _, err := p.Source.kind(); err != nil {
+		return err
+	}
+
+	if len(p.Params.Version) == 0 && len(p.Params.VersionFile) == 0 {
+		return fmt.Errorf("either version or version_file has to be given")
+	}
+
+	return nil
+}
+
+// version returns the published version which is either given directly or read from the version file
+func (p PutParams) version(path string) (string, error) {
+	if len(p.Version) > 0 {
+		return p.Version, nil
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(path, p.VersionFile))
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read version file %s: %s", p.VersionFile, err)
+	}
+
+	version := strings.TrimSpace(string(content))
+
+	if len(version) == 0 {
+		return "", fmt.Errorf("version file %s is empty", p.VersionFile)
+	}
+
+	return version, nil
+}
+
+// parseDuration parses a positive duration which defaults to defaultValue
+func parseDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("%s is not positive", value)
+	}
+
+	return duration, nil
+}
+
+// PutRequest contains the information for a specific Source and the published version
+type PutRequest struct {
+	Source Source    `json:"source"`
+	Params PutParams `json:"params"`
+}
+
+// PutParams contains the published version and how long to wait for it
+type PutParams struct {
+	Version     string `json:"version"`
+	VersionFile string `json:"version_file"`
+	Timeout     string `json:"timeout"`
+	Interval    string `json:"interval"`
+}
+
+// PutResponse contains the published Version and Metadata for the Version
+type PutResponse struct {
+	Version  Version  `json:"version"`
+	Metadata Metadata `json:"metadata,omitempty"`
+}
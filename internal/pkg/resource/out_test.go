@@ -0,0 +1,167 @@
+package resource_test
+
+import (
+	"fmt"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var _ = Describe("Artifacthub Resource Out", func() {
+
+	var (
+		artifacthub        *fakes.FakeArtifactHub
+		putRequest         resource.PutRequest
+		fixedTime          time.Time
+		testPackageVersion *resource.PackageVersion
+		sourceDir          string
+	)
+
+	BeforeEach(func() {
+		artifacthub = new(fakes.FakeArtifactHub)
+		fixedTime = time.Now().UTC()
+		putRequest = resource.PutRequest{
+			Source: resource.Source{
+				RepositoryName: "acme-charts",
+				PackageName:    "my-package-name",
+				ApiKey:         "some-fake-api-key",
+			},
+			Params: resource.PutParams{
+				Version:  "9.2.4",
+				Timeout:  "1s",
+				Interval: "1ms",
+			},
+		}
+		testPackageVersion = &resource.PackageVersion{
+			AppVersion: "8.2.1",
+			ContentUrl: "https://git.local/",
+			TS:         resource.Epoch(fixedTime),
+			Name:       "some-package",
+			Version:    "9.2.4",
+		}
+
+		var err error
+		sourceDir, err = ioutil.TempDir("", "resource-out-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(sourceDir)
+	})
+
+	When("out is called with valid arguments", func() {
+
+		It("should return the published version", func() {
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(1))
+			pkg, _ := artifacthub.ListVersionArgsForCall(0)
+			Expect(pkg).To(Equal(resource.Package{
+				Kind:           "helm",
+				RepositoryName: "acme-charts",
+				PackageName:    "my-package-name",
+				ApiKey:         "some-fake-api-key",
+			}))
+			Expect(response.Version).To(Equal(resource.Version{
+				Version:   "9.2.4",
+				CreatedAt: fixedTime,
+			}))
+			Expect(response.Metadata).To(ContainElement(resource.Metadata{{Name: "version", Value: "9.2.4"}}[0]))
+		})
+
+		It("should poll until the version is available", func() {
+			artifacthub.ListVersionReturnsOnCall(0, nil, fmt.Errorf("not found"))
+			artifacthub.ListVersionReturnsOnCall(1, nil, fmt.Errorf("not found"))
+			artifacthub.ListVersionReturnsOnCall(2, testPackageVersion, nil)
+
+			response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(3))
+			_, version := artifacthub.ListVersionArgsForCall(2)
+			Expect(version).To(Equal("9.2.4"))
+			Expect(response.Version.Version).To(Equal("9.2.4"))
+		})
+
+		It("should read the version from the version file", func() {
+			Expect(ioutil.WriteFile(filepath.Join(sourceDir, "version"), []byte("9.2.4\n"), 0600)).To(Succeed())
+			putRequest.Params.Version = ""
+			putRequest.Params.VersionFile = "version"
+			artifacthub.ListVersionReturns(testPackageVersion, nil)
+
+			_, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, version := artifacthub.ListVersionArgsForCall(0)
+			Expect(version).To(Equal("9.2.4"))
+		})
+	})
+
+	When("out fails", func() {
+
+		It("should return an error when no version is given", func() {
+			putRequest.Params.Version = ""
+
+			response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+		})
+
+		testdata := []struct {
+			description string
+			timeout     string
+			interval    string
+		}{
+			{description: "should return an error when the interval is zero", timeout: "1s", interval: "0s"},
+			{description: "should return an error when the interval is negative", timeout: "1s", interval: "-1s"},
+			{description: "should return an error when the timeout is zero", timeout: "0", interval: "1ms"},
+			{description: "should return an error when the timeout is negative", timeout: "-1m", interval: "1ms"},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(data.description, func() {
+				putRequest.Params.Timeout = data.timeout
+				putRequest.Params.Interval = data.interval
+
+				response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+				Expect(err).To(MatchError(ContainSubstring("is not positive")))
+				Expect(response).To(BeNil())
+				Expect(artifacthub.ListVersionCallCount()).To(Equal(0))
+			})
+		}
+
+		It("should stop polling when artifacthub returns an error other than not found", func() {
+			artifacthub.ListVersionReturns(nil, &resource.APIError{StatusCode: http.StatusUnauthorized})
+
+			response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+			Expect(artifacthub.ListVersionCallCount()).To(Equal(1))
+		})
+
+		It("should return an error when the version does not become available", func() {
+			putRequest.Params.Timeout = "10ms"
+			artifacthub.ListVersionReturns(nil, fmt.Errorf("not found"))
+
+			response, err := resource.Put(putRequest, sourceDir, artifacthub)
+
+			Expect(err).To(MatchError(ContainSubstring("did not become available")))
+			Expect(response).To(BeNil())
+		})
+	})
+
+})
@@ -0,0 +1,329 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxParallelRequests limits the concurrent requests against artifacthub for multiple packages
+const maxParallelRequests = 4
+
+// PackageSource names a single package of a Source with multiple packages
+type PackageSource struct {
+	Kind           string `json:"kind"`
+	RepositoryName string `json:"repository_name"`
+	PackageName    string `json:"package_name"`
+}
+
+// name returns the unique name of the package within a manifest
+func (p PackageSource) name() string {
+	return p.RepositoryName + "/" + p.PackageName
+}
+
+// validatePackages checks that either packages or a single repository and package are given
+func (s Source) validatePackages() error {
+	if len(s.RepositoryName) > 0 || len(s.PackageName) > 0 || len(s.Organization) > 0 || len(s.Search) > 0 {
+		return fmt.Errorf("packages can not be given together with repository_name, package_name, organization or search")
+	}
+
+	names := map[string]bool{}
+
+	for _, p := range s.Packages {
+		if len(p.PackageName) == 0 || len(p.RepositoryName) == 0 {
+			return fmt.Errorf(
+				"package name: %s or repository name: %s of packages should not be empty",
+				p.PackageName,
+				p.RepositoryName,
+			)
+		}
+
+		if names[p.name()] {
+			return fmt.Errorf("package %s is given more than once", p.name())
+		}
+
+		names[p.name()] = true
+	}
+
+	for _, source := range s.packageSources() {
+		if _, err := source.kind(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// packageSources returns a Source with a single package for each of the packages
+func (s Source) packageSources() []Source {
+	var sources []Source
+
+	for _, p := range s.Packages {
+		source := s
+		source.Packages = nil
+		source.RepositoryName = p.RepositoryName
+		source.PackageName = p.PackageName
+
+		if len(p.Kind) > 0 {
+			source.Kind = p.Kind
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
+// checkPackages returns a single composite version of the latest versions of all packages.
+// The requested version is returned unchanged if none of the packages has a new version.
+func (c CheckRequest) checkPackages(repository ArtifactHub) ([]Version, error) {
+	sources := c.Source.packageSources()
+	latest := make([]Version, len(sources))
+
+	err := parallel(len(sources), func(i int) error {
+		versions, err := CheckRequest{Source: sources[i]}.versions(repository)
+
+		if err != nil {
+			return fmt.Errorf("package %s/%s: %w", sources[i].RepositoryName, sources[i].PackageName, err)
+		}
+
+		if len(versions) == 0 {
+			return fmt.Errorf("no version found for package %s/%s", sources[i].RepositoryName, sources[i].PackageName)
+		}
+
+		latest[i] = versions[0]
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	version := compositeVersion(c.Source.Packages, latest)
+
+	if version.Version == c.Version.Version {
+		return []Version{c.Version}, nil
+	}
+
+	version.Changes = manifestChanges(c.Version.Manifest, version.Manifest)
+
+	return []Version{version}, nil
+}
+
+// compositeVersion returns a version identified by the digest of the manifest of all package versions
+func compositeVersion(packages []PackageSource, versions []Version) Version {
+	var entries []string
+	var createdAt time.Time
+
+	for i, version := range versions {
+		entry := fmt.Sprintf("%s@%s", packages[i].name(), version.Version)
+
+		if len(version.Dependencies) > 0 {
+			entry += "#" + version.Dependencies
+		}
+
+		entries = append(entries, entry)
+
+		if version.CreatedAt.After(createdAt) {
+			createdAt = version.CreatedAt
+		}
+	}
+
+	sort.Strings(entries)
+	manifest := strings.Join(entries, ",")
+	sum := sha256.Sum256([]byte(manifest))
+
+	return Version{
+		CreatedAt: createdAt,
+		Version:   hex.EncodeToString(sum[:8]),
+		Manifest:  manifest,
+	}
+}
+
+// manifestChanges returns the entries of the current manifest which are not part of the previous manifest
+func manifestChanges(previous string, current string) string {
+	known := map[string]bool{}
+
+	for _, entry := range strings.Split(previous, ",") {
+		known[entry] = true
+	}
+
+	var changes []string
+
+	for _, entry := range strings.Split(current, ",") {
+		if !known[entry] {
+			changes = append(changes, entry)
+		}
+	}
+
+	return strings.Join(changes, ",")
+}
+
+// parseManifest returns the package versions of the manifest in the order of the manifest
+func parseManifest(manifest string) ([]packageVersion, error) {
+	versions := []packageVersion{}
+
+	if len(manifest) == 0 {
+		return versions, nil
+	}
+
+	for _, entry := range strings.Split(manifest, ",") {
+		entry = strings.SplitN(entry, "#", 2)[0]
+		parts := strings.SplitN(entry, "@", 2)
+
+		if len(parts) != 2 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid manifest entry %s", entry)
+		}
+
+		names := strings.SplitN(parts[0], "/", 2)
+
+		if len(names) != 2 || len(names[0]) == 0 || len(names[1]) == 0 {
+			return nil, fmt.Errorf("invalid manifest entry %s", entry)
+		}
+
+		versions = append(versions, packageVersion{
+			RepositoryName: names[0],
+			PackageName:    names[1],
+			Version:        parts[1],
+		})
+	}
+
+	return versions, nil
+}
+
+// getPackages gets the version of each package of the manifest into a directory <repository_name>/<package_name>
+func getPackages(request GetRequest, path string, repository ArtifactHub) (*GetResponse, error) {
+	versions, err := parseManifest(request.Version.Manifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := map[string]string{}
+
+	for _, version := range versions {
+		manifest[version.name()] = version.Version
+	}
+
+	for _, p := range request.Source.Packages {
+		if _, ok := manifest[p.name()]; !ok {
+			return nil, fmt.Errorf("package %s is not part of the version manifest", p.name())
+		}
+	}
+
+	sources := request.Source.packageSources()
+
+	err = parallel(len(sources), func(i int) error {
+		name := request.Source.Packages[i].name()
+
+		_, err := Get(GetRequest{
+			Source:  sources[i],
+			Version: Version{Version: manifest[name]},
+			Params:  request.Params,
+		}, filepath.Join(path, sources[i].RepositoryName, sources[i].PackageName), repository)
+
+		if err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writeManifest(path, request.Version.Manifest, request.Version.Changes); err != nil {
+		return nil, err
+	}
+
+	var metadata = &Metadata{}
+
+	for _, p := range request.Source.Packages {
+		metadata.append(p.name(), manifest[p.name()])
+	}
+
+	return &GetResponse{
+		Version:  request.Version,
+		Metadata: *metadata,
+	}, nil
+}
+
+// writeManifest writes the versions of all packages of the manifest to packages.json
+// and the changed packages to changes.json. The changed packages are returned.
+func writeManifest(path string, manifest string, changed string) ([]packageVersion, error) {
+	var files = &Metadata{}
+	var changes []packageVersion
+
+	for _, file := range []struct {
+		name     string
+		manifest string
+	}{
+		{name: "packages.json", manifest: manifest},
+		{name: "changes.json", manifest: changed},
+	} {
+		versions, err := parseManifest(file.manifest)
+
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := json.MarshalIndent(versions, "", "  ")
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %s", file.name, err)
+		}
+
+		files.append(file.name, string(content))
+		changes = versions
+	}
+
+	return changes, writeFiles(path, *files)
+}
+
+// packageVersion represents the version of a package within packages.json and changes.json
+type packageVersion struct {
+	RepositoryName string `json:"repository_name"`
+	PackageName    string `json:"package_name"`
+	Version        string `json:"version"`
+}
+
+// name returns the unique name of the package within a manifest
+func (p packageVersion) name() string {
+	return p.RepositoryName + "/" + p.PackageName
+}
+
+// parallel calls fn for 0 to n-1 with at most maxParallelRequests concurrent calls
+// and returns the error of the lowest index
+func parallel(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	semaphore := make(chan struct{}, maxParallelRequests)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			errs[i] = fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"gopkg.in/yaml.v2"
+)
+
+// provenanceFiles represents the files section of a helm provenance file
+type provenanceFiles struct {
+	Files map[string]string `yaml:"files"`
+}
+
+// verifyProvenance verifies the signature of the provenance file of the version with the given keyring
+// and checks that the provenance file contains the digest of the downloaded chart tarball.
+func verifyProvenance(version *PackageVersion, digest string, keyring string, repository ArtifactHub) error {
+	if !version.hasProvenance() {
+		return fmt.Errorf("chart %s version %s is not signed", version.Name, version.Version)
+	}
+
+	keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(keyring))
+
+	if err != nil {
+		return fmt.Errorf("failed to read keyring: %s", err)
+	}
+
+	var prov bytes.Buffer
+
+	if err := repository.Download(version.ContentUrl+".prov", &prov); err != nil {
+		return fmt.Errorf("failed to download provenance file: %w", err)
+	}
+
+	block, _ := clearsign.Decode(prov.Bytes())
+
+	if block == nil {
+		return fmt.Errorf("provenance file of chart %s version %s contains no signature", version.Name, version.Version)
+	}
+
+	if _, err := openpgp.CheckDetachedSignature(keys, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body, nil); err != nil {
+		return fmt.Errorf("invalid signature of chart %s version %s: %s", version.Name, version.Version, err)
+	}
+
+	sections := strings.Split(string(block.Plaintext), "\n...\n")
+	var files provenanceFiles
+
+	if err := yaml.Unmarshal([]byte(sections[len(sections)-1]), &files); err != nil {
+		return fmt.Errorf("failed to parse provenance file: %s", err)
+	}
+
+	filename := chartFilename(version)
+	checksum, ok := files.Files[filename]
+
+	if !ok {
+		return fmt.Errorf("provenance file contains no checksum for %s", filename)
+	}
+
+	if strings.ToLower(strings.TrimPrefix(checksum, "sha256:")) != digest {
+		return fmt.Errorf("checksum of %s does not match the provenance file", filename)
+	}
+
+	return nil
+}
+
+// hasProvenance reports whether the version is signed with a helm provenance file
+func (h PackageVersion) hasProvenance() bool {
+	if !h.Signed {
+		return false
+	}
+
+	if len(h.Signatures) == 0 {
+		return true
+	}
+
+	for _, signature := range h.Signatures {
+		if signature == "prov" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// chartFilename returns the file name of the chart tarball as used in the provenance file
+func chartFilename(version *PackageVersion) string {
+	if u, err := url.Parse(version.ContentUrl); err == nil && strings.HasSuffix(u.Path, ".tgz") {
+		return path.Base(u.Path)
+	}
+	return fmt.Sprintf("%s-%s.tgz", version.Name, version.Version)
+}
@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unixTimestampThreshold separates X-RateLimit-Reset values given as unix timestamp from values given in seconds
+const unixTimestampThreshold = 1000000000
+
+// retryTransport retries requests that failed with a transport error, HTTP 429 or HTTP 5xx.
+//
+// The delay between two attempts grows exponentially with jitter, unless the response
+// tells when to retry by the Retry-After or X-RateLimit-Reset header.
+// A request is not retried anymore if the total wait would exceed maxWait.
+// Requests which are not idempotent are only retried after HTTP 429.
+type retryTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+	maxWait   time.Duration
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var waited time.Duration
+
+	for attempt := 0; ; attempt++ {
+		current, err := retryRequest(request, attempt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := t.attempt(current)
+
+		if err == nil && !retryable(response.StatusCode) {
+			return response, nil
+		}
+
+		if request.Body != nil && request.GetBody == nil {
+			return response, err
+		}
+
+		if !idempotent(request.Method) && (err != nil || response.StatusCode != http.StatusTooManyRequests) {
+			return response, err
+		}
+
+		delay := t.backoff(attempt)
+
+		if err == nil {
+			if d, ok := retryAfter(response.Header, time.Now()); ok {
+				delay = d
+			}
+		}
+
+		if waited+delay > t.maxWait {
+			return response, err
+		}
+
+		if response != nil {
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+
+		waited += delay
+	}
+}
+
+// retryRequest returns the request for the given attempt. Retries get a clone of the request
+// with a fresh body, as a RoundTripper must not modify the given request.
+func retryRequest(request *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 {
+		return request, nil
+	}
+
+	clone := request.Clone(request.Context())
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		clone.Body = body
+	}
+
+	return clone, nil
+}
+
+// idempotent returns true for request methods which can be retried after the server processed them.
+// Other requests are only retried if they were rejected by HTTP 429.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// attempt sends the request once and cancels it after the timeout.
+// The timeout also applies to reading the response body.
+func (t *retryTransport) attempt(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), t.timeout)
+	response, err := t.transport.RoundTrip(request.WithContext(ctx))
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	response.Body = &cancelBody{ReadCloser: response.Body, cancel: cancel}
+
+	return response, nil
+}
+
+// backoff returns the exponential delay with jitter for the given attempt
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.maxDelay
+
+	if attempt < 16 && t.baseDelay<<uint(attempt) < t.maxDelay {
+		delay = t.baseDelay << uint(attempt)
+	}
+
+	half := int64(delay / 2)
+	if half == 0 {
+		return delay
+	}
+
+	return time.Duration(half + rand.Int63n(half)) // #nosec G404 jitter does not need a secure random number
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// retryAfter returns the delay requested by the Retry-After or X-RateLimit-Reset header.
+//
+// Retry-After is either given in seconds or as HTTP date.
+// X-RateLimit-Reset is either given in seconds or as unix timestamp.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); len(value) > 0 {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return nonNegative(time.Duration(seconds) * time.Second), true
+		}
+
+		if date, err := http.ParseTime(value); err == nil {
+			return nonNegative(date.Sub(now)), true
+		}
+	}
+
+	if value := header.Get("X-RateLimit-Reset"); len(value) > 0 {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if seconds >= unixTimestampThreshold {
+				return nonNegative(time.Unix(seconds, 0).Sub(now)), true
+			}
+			return nonNegative(time.Duration(seconds) * time.Second), true
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// cancelBody cancels the context of a request attempt when the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,158 @@
+package resource_test
+
+import (
+	"fmt"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Retry Transport", func() {
+
+	var now = time.Date(2020, 11, 25, 15, 3, 42, 0, time.UTC)
+
+	When("the delay is requested by the response", func() {
+
+		testdata := []struct {
+			description string
+			header      http.Header
+			delay       time.Duration
+			ok          bool
+		}{
+			{description: "Retry-After in seconds", header: http.Header{"Retry-After": {"120"}}, delay: 2 * time.Minute, ok: true},
+			{description: "Retry-After as HTTP date", header: http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, delay: time.Minute, ok: true},
+			{description: "Retry-After as HTTP date in the past", header: http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, delay: 0, ok: true},
+			{description: "X-RateLimit-Reset in seconds", header: http.Header{"X-Ratelimit-Reset": {"30"}}, delay: 30 * time.Second, ok: true},
+			{description: "X-RateLimit-Reset as unix timestamp", header: http.Header{"X-Ratelimit-Reset": {fmt.Sprint(now.Add(45 * time.Second).Unix())}}, delay: 45 * time.Second, ok: true},
+			{description: "Retry-After before X-RateLimit-Reset", header: http.Header{"Retry-After": {"1"}, "X-Ratelimit-Reset": {"30"}}, delay: time.Second, ok: true},
+			{description: "an invalid Retry-After", header: http.Header{"Retry-After": {"soon"}}, delay: 0, ok: false},
+			{description: "no header", header: http.Header{}, delay: 0, ok: false},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(fmt.Sprintf("should parse %s", data.description), func() {
+				delay, ok := resource.RetryAfter(data.header, now)
+
+				Expect(ok).To(Equal(data.ok))
+				Expect(delay).To(Equal(data.delay))
+			})
+		}
+	})
+
+	When("the delay is computed by the backoff", func() {
+
+		testdata := []struct {
+			attempt int
+			min     time.Duration
+			max     time.Duration
+		}{
+			{attempt: 0, min: 500 * time.Millisecond, max: time.Second},
+			{attempt: 2, min: 2 * time.Second, max: 4 * time.Second},
+			{attempt: 5, min: 15 * time.Second, max: 30 * time.Second},
+			{attempt: 64, min: 15 * time.Second, max: 30 * time.Second},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(fmt.Sprintf("should grow exponentially up to the maximum delay for attempt %d", data.attempt), func() {
+				for i := 0; i < 100; i++ {
+					delay := resource.Backoff(time.Second, 30*time.Second, data.attempt)
+
+					Expect(delay).To(BeNumerically(">=", data.min))
+					Expect(delay).To(BeNumerically("<=", data.max))
+				}
+			})
+		}
+	})
+
+	When("a request is retried", func() {
+
+		var (
+			statusCodes []int
+			requests    []*http.Request
+			bodies      []string
+			transport   http.RoundTripper
+		)
+
+		BeforeEach(func() {
+			requests = nil
+			bodies = nil
+			transport = resource.NewRetryTransport(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				requests = append(requests, request)
+
+				if request.Body != nil {
+					body, err := ioutil.ReadAll(request.Body)
+					Expect(err).ToNot(HaveOccurred())
+					bodies = append(bodies, string(body))
+				}
+
+				statusCode := statusCodes[0]
+				statusCodes = statusCodes[1:]
+
+				return &http.Response{
+					StatusCode: statusCode,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}, nil
+			}), time.Second, time.Millisecond, time.Millisecond)
+		})
+
+		testdata := []struct {
+			description string
+			method      string
+			statusCodes []int
+			attempts    int
+			statusCode  int
+		}{
+			{description: "should retry an idempotent request after HTTP 5xx", method: http.MethodGet, statusCodes: []int{503, 500, 200}, attempts: 3, statusCode: 200},
+			{description: "should retry an idempotent request after HTTP 429", method: http.MethodPut, statusCodes: []int{429, 200}, attempts: 2, statusCode: 200},
+			{description: "should not retry a request which is not idempotent after HTTP 5xx", method: http.MethodPost, statusCodes: []int{503, 200}, attempts: 1, statusCode: 503},
+			{description: "should retry a request which is not idempotent after HTTP 429", method: http.MethodPost, statusCodes: []int{429, 200}, attempts: 2, statusCode: 200},
+			{description: "should not retry a request which failed with HTTP 4xx", method: http.MethodGet, statusCodes: []int{404, 200}, attempts: 1, statusCode: 404},
+		}
+
+		for _, data := range testdata {
+			data := data
+			It(data.description, func() {
+				statusCodes = data.statusCodes
+				request, err := http.NewRequest(data.method, "https://artifacthub.io/api/v1/some", strings.NewReader("some body"))
+				Expect(err).ToNot(HaveOccurred())
+
+				response, err := transport.RoundTrip(request)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.StatusCode).To(Equal(data.statusCode))
+				Expect(requests).To(HaveLen(data.attempts))
+				Expect(bodies).To(HaveLen(data.attempts))
+				for _, body := range bodies {
+					Expect(body).To(Equal("some body"))
+				}
+			})
+		}
+
+		It("should not modify the given request", func() {
+			statusCodes = []int{503, 200}
+			request, err := http.NewRequest(http.MethodGet, "https://artifacthub.io/api/v1/some", strings.NewReader("some body"))
+			Expect(err).ToNot(HaveOccurred())
+			body := request.Body
+
+			_, err = transport.RoundTrip(request)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(request.Body).To(BeIdenticalTo(body))
+			Expect(requests[1]).ToNot(BeIdenticalTo(request))
+			Expect(requests[1].URL.String()).To(Equal(request.URL.String()))
+		})
+	})
+})
+
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
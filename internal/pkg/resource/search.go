@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCandidates limits the candidates listed for an ambiguous search
+const maxCandidates = 10
+
+// validateSearch checks that a search is not combined with a package name or packages
+func (s Source) validateSearch() error {
+	if len(s.PackageName) > 0 || len(s.Packages) > 0 {
+		return fmt.Errorf("search can not be given together with package_name or packages")
+	}
+	return nil
+}
+
+// resolve returns the Source of the single package found by the search of the Source.
+// Only the first page of results is requested, the search fails if it finds more than one package.
+func (s Source) resolve(repository ArtifactHub) (Source, error) {
+	results, err := repository.SearchPackages(s.pkg(), SearchQuery{
+		Text:              s.Search,
+		Repository:        s.RepositoryName,
+		Organization:      s.Organization,
+		Kind:              s.Kind,
+		VerifiedPublisher: s.VerifiedPublisher,
+		Official:          s.Official,
+		Limit:             maxCandidates + 1,
+	})
+
+	if err != nil {
+		return s, fmt.Errorf("failed to search packages: %w", explain(err))
+	}
+
+	if len(results) == 0 {
+		return s, fmt.Errorf("no package found for search %s", s.Search)
+	}
+
+	if len(results) > 1 {
+		return s, fmt.Errorf(
+			"search %s is ambiguous, narrow it down by repository_name, organization or kind, candidates are: %s",
+			s.Search,
+			candidates(results),
+		)
+	}
+
+	kind, ok := kindName(results[0].Repository.Kind)
+
+	if !ok {
+		return s, fmt.Errorf("unknown kind %d of package %s/%s", results[0].Repository.Kind, results[0].Repository.Name, results[0].Name)
+	}
+
+	s.Search = ""
+	s.Organization = ""
+	s.Kind = kind
+	s.RepositoryName = results[0].Repository.Name
+	s.PackageName = results[0].Name
+
+	return s, nil
+}
+
+// checkSearch returns the versions of the package found by the search with the package pinned in each version
+func (c CheckRequest) checkSearch(repository ArtifactHub) ([]Version, error) {
+	source, err := c.Source.resolve(repository)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := CheckRequest{Source: source, Version: c.Version}.versions(repository)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make([]Version, len(versions))
+
+	for i, version := range versions {
+		version.Package = source.pinned()
+		pinned[i] = version
+	}
+
+	return pinned, nil
+}
+
+// pinned returns the kind, repository name and package name of a resolved Source as stored in its versions
+func (s Source) pinned() string {
+	return fmt.Sprintf("%s/%s/%s", s.Kind, s.RepositoryName, s.PackageName)
+}
+
+// pin returns the Source of the package pinned in a version by the check instead of searching it again
+func (s Source) pin(pinned string) (Source, error) {
+	parts := strings.Split(pinned, "/")
+
+	if len(parts) != 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return s, fmt.Errorf("invalid package %s of the version: expected <kind>/<repository_name>/<package_name>", pinned)
+	}
+
+	s.Search = ""
+	s.Organization = ""
+	s.Kind = parts[0]
+	s.RepositoryName = parts[1]
+	s.PackageName = parts[2]
+
+	if _, err := s.kind(); err != nil {
+		return s, err
+	}
+
+	return s, nil
+}
+
+// candidates returns the names of the found packages with their kind
+func candidates(results []SearchResult) string {
+	var names []string
+
+	for i, result := range results {
+		if i == maxCandidates {
+			names = append(names, "and more")
+			break
+		}
+
+		kind, _ := kindName(result.Repository.Kind)
+		names = append(names, fmt.Sprintf("%s/%s (%s)", result.Repository.Name, result.Name, kind))
+	}
+
+	return strings.Join(names, ", ")
+}
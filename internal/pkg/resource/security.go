@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// severities contains the severities of a security report in ascending order
+var severities = []string{"unknown", "low", "medium", "high", "critical"}
+
+// SeverityNone can be used as max_severity to reject any vulnerability
+const SeverityNone = "none"
+
+// securitySummary returns the security report summary of the version as JSON with counts for every severity
+func securitySummary(version *PackageVersion) ([]byte, error) {
+	summary := map[string]int{}
+
+	for _, severity := range severities {
+		summary[severity] = version.SecurityReportSummary[severity]
+	}
+
+	return json.MarshalIndent(summary, "", "  ")
+}
+
+// checkSeverity returns an error if the version has vulnerabilities with a higher severity than maxSeverity
+func checkSeverity(version *PackageVersion, maxSeverity string) error {
+	if version.SecurityReportSummary == nil {
+		return fmt.Errorf("no security report available for %s version %s", version.Name, version.Version)
+	}
+
+	limit, err := severityRank(maxSeverity)
+
+	if err != nil {
+		return err
+	}
+
+	for rank, severity := range severities {
+		if rank > limit && version.SecurityReportSummary[severity] > 0 {
+			return fmt.Errorf(
+				"%s version %s has %d vulnerabilities with severity %s which exceeds max_severity %s",
+				version.Name,
+				version.Version,
+				version.SecurityReportSummary[severity],
+				severity,
+				maxSeverity,
+			)
+		}
+	}
+
+	return nil
+}
+
+// severityRank returns the position of the severity in severities or -1 for SeverityNone
+func severityRank(severity string) (int, error) {
+	if strings.ToLower(severity) == SeverityNone {
+		return -1, nil
+	}
+
+	for rank, s := range severities {
+		if s == strings.ToLower(severity) {
+			return rank, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid max_severity %s: expected one of %s or %s", severity, SeverityNone, strings.Join(severities, ", "))
+}
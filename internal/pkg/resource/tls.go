@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// scopedTransport sends requests to the host of artifacthub with the configured transport
+// and all other requests, e.g. chart downloads from third-party hosts, with the fallback,
+// so client certificates of a self-hosted artifacthub are not sent to other hosts.
+type scopedTransport struct {
+	host      string
+	transport http.RoundTripper
+	fallback  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *scopedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if len(t.host) == 0 || strings.EqualFold(request.URL.Host, t.host) {
+		return t.transport.RoundTrip(request)
+	}
+
+	return t.fallback.RoundTrip(request)
+}
+
+// withoutCertificates returns a copy of the TLS configuration without client certificates
+func withoutCertificates(config *tls.Config) *tls.Config {
+	if config == nil {
+		return nil
+	}
+
+	config = config.Clone()
+	config.Certificates = nil
+
+	return config
+}
+
+// tlsConfig returns the TLS configuration of the Source or nil if the defaults should be used
+func (s Source) tlsConfig() (*tls.Config, error) {
+	if len(s.CACert) == 0 && len(s.ClientCert) == 0 && len(s.ClientKey) == 0 && !s.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: s.InsecureSkipVerify, // #nosec G402 explicitly requested for self-hosted instances
+	}
+
+	if len(s.CACert) > 0 {
+		pool, err := x509.SystemCertPool()
+
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(s.CACert)) {
+			return nil, fmt.Errorf("invalid ca_cert: no PEM encoded certificate found")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if (len(s.ClientCert) == 0) != (len(s.ClientKey) == 0) {
+		return nil, fmt.Errorf("client_cert and client_key have to be given together")
+	}
+
+	if len(s.ClientCert) > 0 {
+		certificate, err := tls.X509KeyPair([]byte(s.ClientCert), []byte(s.ClientKey))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid client_cert or client_key: %s", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,89 @@
+package resource_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/hdisysteme/artifacthub-resource/internal/pkg/resource"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+)
+
+var _ = Describe("Scoped Transport", func() {
+
+	var (
+		configured []string
+		fallback   []string
+	)
+
+	newTransport := func(host string) http.RoundTripper {
+		configured = nil
+		fallback = nil
+
+		return resource.NewScopedTransport(host,
+			roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				configured = append(configured, request.URL.String())
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}),
+			roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				fallback = append(fallback, request.URL.String())
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}),
+		)
+	}
+
+	roundTrip := func(transport http.RoundTripper, url string) {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("should use the configured transport only for the host of artifacthub", func() {
+		transport := newTransport("artifacthub.example.com")
+
+		roundTrip(transport, "https://artifacthub.example.com/api/v1/packages/helm/acme-charts/some-package")
+		roundTrip(transport, "https://ARTIFACTHUB.example.com/charts/some-package-9.2.4.tgz")
+		roundTrip(transport, "https://git.local/charts/some-package-9.2.4.tgz")
+		roundTrip(transport, "https://artifacthub.example.com:8443/charts/some-package-9.2.4.tgz")
+
+		Expect(configured).To(Equal([]string{
+			"https://artifacthub.example.com/api/v1/packages/helm/acme-charts/some-package",
+			"https://ARTIFACTHUB.example.com/charts/some-package-9.2.4.tgz",
+		}))
+		Expect(fallback).To(Equal([]string{
+			"https://git.local/charts/some-package-9.2.4.tgz",
+			"https://artifacthub.example.com:8443/charts/some-package-9.2.4.tgz",
+		}))
+	})
+
+	It("should use the configured transport for all hosts without a host", func() {
+		transport := newTransport("")
+
+		roundTrip(transport, "https://git.local/charts/some-package-9.2.4.tgz")
+
+		Expect(configured).To(HaveLen(1))
+		Expect(fallback).To(BeEmpty())
+	})
+
+	It("should keep the CAs but not the client certificates for other hosts", func() {
+		config := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			RootCAs:            x509.NewCertPool(),
+			InsecureSkipVerify: true, // #nosec G402 test configuration
+			Certificates:       []tls.Certificate{{}},
+		}
+
+		other := resource.WithoutCertificates(config)
+
+		Expect(other.RootCAs).To(BeIdenticalTo(config.RootCAs))
+		Expect(other.InsecureSkipVerify).To(BeTrue())
+		Expect(other.Certificates).To(BeEmpty())
+		Expect(config.Certificates).To(HaveLen(1))
+	})
+
+	It("should keep the default TLS configuration for other hosts", func() {
+		Expect(resource.WithoutCertificates(nil)).To(BeNil())
+	})
+})